@@ -2,13 +2,16 @@ package cursor
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
-const defaultBlinkSpeed = time.Millisecond * 530
+// DefaultBlinkSpeed is the interval at which the cursor blinks by default.
+const DefaultBlinkSpeed = time.Millisecond * 530
 
 // initialBlinkMsg initializes cursor blinking.
 type initialBlinkMsg struct{}
@@ -57,6 +60,12 @@ type Model struct {
 	// TextStyle is the style used for the cursor when it is hidden (when blinking).
 	// I.e. displaying normal text.
 	TextStyle lipgloss.Style
+	// Glyph, when set, is shown in place of the character under the cursor
+	// while the cursor is visible, rendered with Style. Use it for bar or
+	// underscore cursors such as "▏" or "_". It's padded or cut to the width
+	// of the character it replaces. By default, the character under the
+	// cursor is shown in reverse video, as a block.
+	Glyph string
 
 	// char is the character under the cursor
 	char string
@@ -77,7 +86,7 @@ type Model struct {
 // New creates a new model with default settings.
 func New() Model {
 	return Model{
-		BlinkSpeed: defaultBlinkSpeed,
+		BlinkSpeed: DefaultBlinkSpeed,
 
 		Blink: true,
 		mode:  CursorBlink,
@@ -175,6 +184,17 @@ func (m *Model) BlinkCmd() tea.Cmd {
 	}
 }
 
+// glyph returns Glyph padded or truncated to the width of the character under
+// the cursor, so that the line doesn't change width as the cursor blinks.
+func (m Model) glyph() string {
+	w := ansi.StringWidth(m.char)
+	g := ansi.Truncate(m.Glyph, w, "")
+	if n := w - ansi.StringWidth(g); n > 0 {
+		g += strings.Repeat(" ", n)
+	}
+	return g
+}
+
 // Blink is a command used to initialize cursor blinking.
 func Blink() tea.Msg {
 	return initialBlinkMsg{}
@@ -207,5 +227,8 @@ func (m Model) View() string {
 	if m.Blink {
 		return m.TextStyle.Inline(true).Render(m.char)
 	}
+	if m.Glyph != "" {
+		return m.Style.Inline(true).Render(m.glyph())
+	}
 	return m.Style.Inline(true).Reverse(true).Render(m.char)
 }
@@ -0,0 +1,39 @@
+package cursor
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestDefaultBlinkSpeed(t *testing.T) {
+	if m := New(); m.BlinkSpeed != DefaultBlinkSpeed {
+		t.Fatalf("expected blink speed %s but got %s", DefaultBlinkSpeed, m.BlinkSpeed)
+	}
+}
+
+func TestGlyphWidth(t *testing.T) {
+	m := New()
+
+	// The glyph keeps the width of the character under the cursor in either
+	// phase of the blink.
+	for _, tc := range []struct {
+		char, glyph, expected string
+	}{
+		{"日", "_", "_ "},
+		{"a", "_", "_"},
+		{"a", "日", " "},
+		{"日", "▏▏▏", "▏▏"},
+	} {
+		m.Glyph = tc.glyph
+		m.SetChar(tc.char)
+		m.Blink = false
+		if view := ansi.Strip(m.View()); view != tc.expected {
+			t.Fatalf("expected %q for glyph %q on %q but got %q", tc.expected, tc.glyph, tc.char, view)
+		}
+		m.Blink = true
+		if w, expected := ansi.StringWidth(m.View()), ansi.StringWidth(tc.char); w != expected {
+			t.Fatalf("expected the view to stay %d columns wide but got %d", expected, w)
+		}
+	}
+}
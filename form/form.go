@@ -0,0 +1,192 @@
+// Package form provides a Bubble Tea component for a group of text inputs,
+// handling moving the focus between them and pasting across them.
+package form
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Internal messages for clipboard operations.
+type (
+	pasteMsg    string
+	pasteErrMsg struct{ error }
+)
+
+// KeyMap is the key bindings for moving the focus between the inputs.
+type KeyMap struct {
+	Next key.Binding
+	Prev key.Binding
+}
+
+// DefaultKeyMap is the default set of key bindings for moving the focus
+// between the inputs.
+var DefaultKeyMap = KeyMap{
+	Next: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next field")),
+	Prev: key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous field")),
+}
+
+// Model is the Bubble Tea model for a form of text inputs.
+type Model struct {
+	// Inputs are the fields of the form, from top to bottom.
+	Inputs []textinput.Model
+
+	// KeyMap encodes the keybindings for moving the focus. While the focused
+	// input's suggestion menu is open, keys go to the input instead.
+	KeyMap KeyMap
+
+	focus int
+}
+
+// New creates a new form of the given inputs with the first one focused.
+func New(inputs ...textinput.Model) Model {
+	m := Model{
+		Inputs: inputs,
+		KeyMap: DefaultKeyMap,
+	}
+	m.Focus(0)
+	return m
+}
+
+// Focused returns the index of the focused input.
+func (m Model) Focused() int {
+	return m.focus
+}
+
+// Focus focuses the input at index i, wrapping around at either end, and
+// blurs the others.
+func (m *Model) Focus(i int) tea.Cmd {
+	if len(m.Inputs) == 0 {
+		m.focus = 0
+		return nil
+	}
+	m.focus = (i%len(m.Inputs) + len(m.Inputs)) % len(m.Inputs)
+
+	var cmd tea.Cmd
+	for i := range m.Inputs {
+		if i == m.focus {
+			cmd = m.Inputs[i].Focus()
+		} else {
+			m.Inputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+// Next focuses the next input, wrapping around to the first one after the
+// last.
+func (m *Model) Next() tea.Cmd {
+	return m.Focus(m.focus + 1)
+}
+
+// Prev focuses the previous input, wrapping around to the last one before the
+// first.
+func (m *Model) Prev() tea.Cmd {
+	return m.Focus(m.focus - 1)
+}
+
+// Values returns the values of the inputs.
+func (m Model) Values() []string {
+	values := make([]string, len(m.Inputs))
+	for i, input := range m.Inputs {
+		values[i] = input.Value()
+	}
+	return values
+}
+
+// Init exists to satisfy the tea.Model interface.
+func (m Model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update is the Bubble Tea update loop. Pasted text containing tabs or
+// newlines is split across the inputs, starting with the focused one, and
+// the focus moves to the input the paste ends in. That goes for both
+// bracketed pastes and the focused input's Paste key, which the form reads
+// the clipboard for. Other messages are passed on to all of the inputs.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if len(m.Inputs) == 0 {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.Inputs[m.focus].SuggestionMenuOpen() {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.KeyMap.Next):
+			return m, m.Next()
+		case key.Matches(msg, m.KeyMap.Prev):
+			return m, m.Prev()
+		case key.Matches(msg, m.Inputs[m.focus].KeyMap.Paste):
+			return m, Paste
+		case msg.Paste && strings.ContainsAny(string(msg.Runes), "\t\n"):
+			return m, m.paste(string(msg.Runes))
+		}
+
+	case pasteMsg:
+		if strings.ContainsAny(string(msg), "\t\n") {
+			return m, m.paste(string(msg))
+		}
+		var cmd tea.Cmd
+		paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(msg), Paste: true}
+		m.Inputs[m.focus], cmd = m.Inputs[m.focus].Update(paste)
+		return m, cmd
+
+	case pasteErrMsg:
+		m.Inputs[m.focus].Err = msg
+		return m, nil
+	}
+
+	cmds := make([]tea.Cmd, len(m.Inputs))
+	for i := range m.Inputs {
+		m.Inputs[i], cmds[i] = m.Inputs[i].Update(msg)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// paste pastes each tab or newline separated part of s into the next input.
+// Parts beyond the last input are dropped.
+func (m *Model) paste(s string) tea.Cmd {
+	s = strings.NewReplacer("\r\n", "\n", "\t", "\n").Replace(s)
+	parts := strings.Split(strings.TrimRight(s, "\n"), "\n")
+
+	var cmds []tea.Cmd
+	for i, part := range parts {
+		if i > 0 {
+			if m.focus == len(m.Inputs)-1 {
+				break
+			}
+			cmds = append(cmds, m.Next())
+		}
+		var cmd tea.Cmd
+		paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(part), Paste: true}
+		m.Inputs[m.focus], cmd = m.Inputs[m.focus].Update(paste)
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...)
+}
+
+// Paste is a command for pasting from the clipboard into the form, like
+// textinput.Paste but split across the inputs.
+func Paste() tea.Msg {
+	str, err := clipboard.ReadAll()
+	if err != nil {
+		return pasteErrMsg{err}
+	}
+	return pasteMsg(str)
+}
+
+// View renders the inputs, one per line.
+func (m Model) View() string {
+	views := make([]string, len(m.Inputs))
+	for i, input := range m.Inputs {
+		views[i] = input.View()
+	}
+	return strings.Join(views, "\n")
+}
@@ -0,0 +1,102 @@
+package form
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newForm(n int) Model {
+	inputs := make([]textinput.Model, n)
+	for i := range inputs {
+		inputs[i] = textinput.New()
+	}
+	return New(inputs...)
+}
+
+func assertFocus(t *testing.T, m Model, expected int) {
+	t.Helper()
+	if m.Focused() != expected {
+		t.Fatalf("expected input %d to be focused but got %d", expected, m.Focused())
+	}
+	for i, input := range m.Inputs {
+		if input.Focused() != (i == expected) {
+			t.Fatalf("expected only input %d to be focused, but input %d is %v", expected, i, input.Focused())
+		}
+	}
+}
+
+func TestFocusWrapAround(t *testing.T) {
+	tab := tea.KeyMsg{Type: tea.KeyTab}
+	shiftTab := tea.KeyMsg{Type: tea.KeyShiftTab}
+
+	m := newForm(3)
+	assertFocus(t, m, 0)
+
+	m, _ = m.Update(tab)
+	m, _ = m.Update(tab)
+	assertFocus(t, m, 2)
+	m, _ = m.Update(tab)
+	assertFocus(t, m, 0)
+
+	m, _ = m.Update(shiftTab)
+	assertFocus(t, m, 2)
+	m, _ = m.Update(shiftTab)
+	assertFocus(t, m, 1)
+}
+
+func TestValues(t *testing.T) {
+	m := newForm(3)
+
+	// Keys only reach the focused input.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ada")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1815")})
+
+	if values, expected := m.Values(), []string{"ada", "", "1815"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %q but got %q", expected, values)
+	}
+}
+
+func TestPasteAcrossInputs(t *testing.T) {
+	m := newForm(3)
+	m.Focus(1)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a\tb\r\nc\n"), Paste: true})
+	if values, expected := m.Values(), []string{"", "a", "b"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %q but got %q", expected, values)
+	}
+	assertFocus(t, m, 2)
+
+	// Empty parts skip an input.
+	m.Focus(0)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x\t\ty"), Paste: true})
+	if values, expected := m.Values(), []string{"x", "a", "by"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %q but got %q", expected, values)
+	}
+}
+
+func TestClipboardPasteAcrossInputs(t *testing.T) {
+	m := newForm(3)
+
+	// The focused input's paste key reads the clipboard for the form.
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlV})
+	if cmd == nil {
+		t.Fatal("expected a command to read the clipboard")
+	}
+
+	m, _ = m.Update(pasteMsg("a\tb\r\nc"))
+	if values, expected := m.Values(), []string{"a", "b", "c"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %q but got %q", expected, values)
+	}
+	assertFocus(t, m, 2)
+
+	// Without separators it goes to the focused input.
+	m, _ = m.Update(pasteMsg("d"))
+	if values, expected := m.Values(), []string{"a", "b", "cd"}; !reflect.DeepEqual(values, expected) {
+		t.Fatalf("expected values %q but got %q", expected, values)
+	}
+}
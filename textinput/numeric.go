@@ -0,0 +1,175 @@
+package textinput
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// NumericKeyMap is the key bindings for stepping a NumericModel's value.
+type NumericKeyMap struct {
+	Increment key.Binding
+	Decrement key.Binding
+}
+
+// DefaultNumericKeyMap is the default set of key bindings for stepping a
+// NumericModel's value.
+var DefaultNumericKeyMap = NumericKeyMap{
+	Increment: key.NewBinding(key.WithKeys("up")),
+	Decrement: key.NewBinding(key.WithKeys("down")),
+}
+
+// NumericModel is a text input that only accepts numbers. Edits that would
+// leave something other than a (partially typed) number are rejected.
+type NumericModel struct {
+	Model
+
+	// Min and Max bound the value when it's stepped and when the input is
+	// blurred. If Max isn't greater than Min, the value is unbounded.
+	Min float64
+	Max float64
+
+	// Step is the amount the value is incremented or decremented by.
+	Step float64
+
+	// NumericKeyMap encodes the key bindings for stepping the value.
+	NumericKeyMap NumericKeyMap
+}
+
+// NewNumeric creates a new numeric input with default settings.
+func NewNumeric() NumericModel {
+	m := NumericModel{
+		Model:         New(),
+		Step:          1,
+		NumericKeyMap: DefaultNumericKeyMap,
+	}
+	m.CharFilter = isNumberRune
+	return m
+}
+
+// Float returns the value as a float.
+func (m NumericModel) Float() (float64, error) {
+	return strconv.ParseFloat(m.Value(), 64)
+}
+
+// Int returns the value as an int.
+func (m NumericModel) Int() (int, error) {
+	return strconv.Atoi(m.Value())
+}
+
+// Blur removes the focus state on the model and clamps the value to Min and
+// Max.
+func (m *NumericModel) Blur() {
+	m.Model.Blur()
+	if v, err := m.Float(); err == nil && m.clamp(v) != v {
+		m.setNumber(m.clamp(v), m.precision())
+	}
+}
+
+// Update is the Bubble Tea update loop. OnChange is only called for edits
+// that are accepted.
+func (m NumericModel) Update(msg tea.Msg) (NumericModel, tea.Cmd) {
+	if !m.focus {
+		return m, nil
+	}
+
+	oldValue := m.Value()
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case key.Matches(msg, m.NumericKeyMap.Increment):
+			m.step(m.Step)
+			m.changed(oldValue)
+			return m, nil
+		case key.Matches(msg, m.NumericKeyMap.Decrement):
+			m.step(-m.Step)
+			m.changed(oldValue)
+			return m, nil
+		}
+	}
+
+	// Keep a copy of the state from before the edit in case it's rejected.
+	prev := m.Model
+	prev.value = append([]rune(nil), m.value...)
+
+	// The inner input mustn't report values that are about to be rejected.
+	onChange := m.OnChange
+	m.OnChange = nil
+
+	var cmd tea.Cmd
+	m.Model, cmd = m.Model.Update(msg)
+	m.OnChange = onChange
+	if !isPartialNumber(m.Value()) {
+		m.Model = prev
+	}
+	m.changed(oldValue)
+	return m, cmd
+}
+
+// changed calls OnChange if the value differs from oldValue.
+func (m NumericModel) changed(oldValue string) {
+	if m.OnChange != nil && m.Value() != oldValue {
+		m.OnChange(m.Value())
+	}
+}
+
+// step adds delta to the value, starting from zero if the value isn't a
+// number yet.
+func (m *NumericModel) step(delta float64) {
+	v, _ := m.Float()
+	m.setNumber(m.clamp(v+delta), m.precision())
+}
+
+// setNumber sets the value to v with the given number of decimals.
+func (m *NumericModel) setNumber(v float64, decimals int) {
+	prev := m.snapshot()
+	m.SetValue(strconv.FormatFloat(v, 'f', decimals, 64))
+	m.CursorEnd()
+	m.pushUndo(prev)
+}
+
+// precision returns the number of decimals in the value or the step,
+// whichever has more. Formatting with it avoids floating point noise like
+// 0.30000000000000004.
+func (m NumericModel) precision() int {
+	return max(decimals(m.Value()), decimals(strconv.FormatFloat(m.Step, 'f', -1, 64)))
+}
+
+// clamp bounds v to Min and Max, if set.
+func (m NumericModel) clamp(v float64) float64 {
+	if m.Max <= m.Min {
+		return v
+	}
+	return math.Max(m.Min, math.Min(m.Max, v))
+}
+
+// decimals returns the number of digits after the decimal point in s.
+func decimals(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// isNumberRune reports whether r can be part of a number.
+func isNumberRune(r rune) bool {
+	return r >= '0' && r <= '9' || r == '-' || r == '.'
+}
+
+// isPartialNumber reports whether s is a number, or the beginning of one such
+// as "-" or "1.".
+func isPartialNumber(s string) bool {
+	s = strings.TrimPrefix(s, "-")
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s = s[:i] + s[i+1:]
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
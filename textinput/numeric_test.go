@@ -0,0 +1,105 @@
+package textinput
+
+import (
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func sendNumericString(m NumericModel, str string) NumericModel {
+	for _, k := range []rune(str) {
+		m, _ = m.Update(keyPress(k))
+	}
+	return m
+}
+
+func Test_NumericRejectsNonNumbers(t *testing.T) {
+	numeric := NewNumeric()
+	numeric.Focus()
+
+	numeric = sendNumericString(numeric, "-1a2.5.x3")
+	if v := numeric.Value(); v != "-12.53" {
+		t.Fatalf("Error: expected %q but was %q", "-12.53", v)
+	}
+	if f, err := numeric.Float(); err != nil || f != -12.53 {
+		t.Fatalf("Error: expected -12.53 but got %v (%v)", f, err)
+	}
+	if _, err := numeric.Int(); err == nil {
+		t.Fatal("Error: expected a fractional value not to be an int")
+	}
+
+	// Pasted text is checked as a whole.
+	numeric.Reset()
+	numeric, _ = numeric.Update(pasteMsg("12-3"))
+	if v := numeric.Value(); v != "" {
+		t.Fatalf("Error: expected the paste to be rejected but value was %q", v)
+	}
+	numeric, _ = numeric.Update(pasteMsg("42"))
+	if i, err := numeric.Int(); err != nil || i != 42 {
+		t.Fatalf("Error: expected 42 but got %v (%v)", i, err)
+	}
+}
+
+func Test_NumericStep(t *testing.T) {
+	numeric := NewNumeric()
+	numeric.Step = 0.1
+	numeric.Focus()
+
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	// An empty value steps from zero.
+	numeric, _ = numeric.Update(up)
+	numeric, _ = numeric.Update(up)
+	numeric, _ = numeric.Update(up)
+	if v := numeric.Value(); v != "0.3" {
+		t.Fatalf("Error: expected %q but was %q", "0.3", v)
+	}
+
+	numeric, _ = numeric.Update(down)
+	if v := numeric.Value(); v != "0.2" {
+		t.Fatalf("Error: expected %q but was %q", "0.2", v)
+	}
+}
+
+func Test_NumericClamp(t *testing.T) {
+	numeric := NewNumeric()
+	numeric.Min = 1
+	numeric.Max = 10
+	numeric.Step = 5
+	numeric.Focus()
+
+	numeric = sendNumericString(numeric, "8")
+	numeric, _ = numeric.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if v := numeric.Value(); v != "10" {
+		t.Fatalf("Error: expected stepping to clamp to %q but was %q", "10", v)
+	}
+
+	// Out of range values can be typed, and are clamped on blur.
+	numeric.Reset()
+	numeric = sendNumericString(numeric, "-3")
+	if v := numeric.Value(); v != "-3" {
+		t.Fatalf("Error: expected %q but was %q", "-3", v)
+	}
+	numeric.Blur()
+	if v := numeric.Value(); v != "1" {
+		t.Fatalf("Error: expected blurring to clamp to %q but was %q", "1", v)
+	}
+}
+
+func Test_NumericOnChange(t *testing.T) {
+	var changes []string
+	numeric := NewNumeric()
+	numeric.OnChange = func(s string) {
+		changes = append(changes, s)
+	}
+	numeric.Focus()
+
+	// Rejected edits aren't reported.
+	numeric = sendNumericString(numeric, "1-2")
+	numeric, _ = numeric.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if expected := []string{"1", "12", "13"}; !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Error: expected changes %q but got %q", expected, changes)
+	}
+}
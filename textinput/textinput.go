@@ -2,9 +2,12 @@ package textinput
 
 import (
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/cursor"
@@ -16,12 +19,59 @@ import (
 	"github.com/rivo/uniseg"
 )
 
+// Internal ID management. Used to ensure that maskLastMsgs are received
+// only by the input that sent them.
+var (
+	lastID int
+	idMtx  sync.Mutex
+)
+
+// Return the next ID we should use on the Model.
+func nextID() int {
+	idMtx.Lock()
+	defer idMtx.Unlock()
+	lastID++
+	return lastID
+}
+
 // Internal messages for clipboard operations.
 type (
 	pasteMsg    string
 	pasteErrMsg struct{ error }
 )
 
+// maskLastMsg masks the last typed character again once EchoRevealDuration
+// has passed.
+type maskLastMsg struct {
+	id  int
+	tag int
+}
+
+const (
+	defaultMaxUndo = 50
+
+	// maskDigit is the placeholder for a digit in a Mask.
+	maskDigit = '#'
+
+	// maxKillRing is the number of killed texts kept for yanking.
+	maxKillRing = 10
+)
+
+// killDirection is the direction text was killed in, relative to the cursor.
+type killDirection int
+
+const (
+	killNone killDirection = iota
+	killForward
+	killBackward
+)
+
+// snapshot is the state of the input at a point in its edit history.
+type snapshot struct {
+	value string
+	pos   int
+}
+
 // EchoMode sets the input behavior of the text input field.
 type EchoMode int
 
@@ -38,6 +88,50 @@ const (
 	EchoNone
 )
 
+// TruncateMode sets how a value that's wider than the input is displayed
+// while the input is blurred.
+type TruncateMode int
+
+const (
+	// TruncateNone scrolls the value horizontally, keeping the cursor in
+	// view. This is the default behavior.
+	TruncateNone TruncateMode = iota
+
+	// TruncateEnd shows the start of the value, ending in an ellipsis.
+	TruncateEnd
+
+	// TruncateMiddle shows the start and the end of the value, with an
+	// ellipsis in between.
+	TruncateMiddle
+)
+
+// PasteNewlineBehavior sets what happens to newlines in pasted text.
+type PasteNewlineBehavior int
+
+const (
+	// PasteNewlineSpace replaces each newline with a space. This is the
+	// default behavior.
+	PasteNewlineSpace PasteNewlineBehavior = iota
+
+	// PasteNewlineSubmit pastes the text up to the first newline and submits
+	// the input. Text after the first newline is discarded.
+	PasteNewlineSubmit
+)
+
+// SubmitMsg is sent when the input is submitted, carrying its value.
+type SubmitMsg struct {
+	Value string
+}
+
+// RedrawMsg is sent when the Redraw key is pressed, which conventionally
+// redraws the screen. The input doesn't act on it; the host can, for example
+// with tea.ClearScreen.
+type RedrawMsg struct{}
+
+func redraw() tea.Msg {
+	return RedrawMsg{}
+}
+
 // ValidateFunc is a function that returns an error if the input is invalid.
 type ValidateFunc func(string) error
 
@@ -59,6 +153,23 @@ type KeyMap struct {
 	AcceptSuggestion        key.Binding
 	NextSuggestion          key.Binding
 	PrevSuggestion          key.Binding
+	SelectCharacterForward  key.Binding
+	SelectCharacterBackward key.Binding
+	Undo                    key.Binding
+	Redo                    key.Binding // alt+z, as ctrl+y yanks and terminals send ctrl+shift+z as ctrl+z
+	HistoryPrevious         key.Binding
+	HistoryNext             key.Binding
+	UppercaseWordForward    key.Binding
+	LowercaseWordForward    key.Binding
+	CapitalizeWordForward   key.Binding
+	MenuNextSuggestion      key.Binding
+	MenuPrevSuggestion      key.Binding
+	MenuAcceptSuggestion    key.Binding
+	Yank                    key.Binding
+	SelectAll               key.Binding
+	ToggleReveal            key.Binding
+	Submit                  key.Binding
+	Redraw                  key.Binding
 }
 
 // DefaultKeyMap is the default set of key bindings for navigating and acting
@@ -80,6 +191,23 @@ var DefaultKeyMap = KeyMap{
 	AcceptSuggestion:        key.NewBinding(key.WithKeys("tab")),
 	NextSuggestion:          key.NewBinding(key.WithKeys("down", "ctrl+n")),
 	PrevSuggestion:          key.NewBinding(key.WithKeys("up", "ctrl+p")),
+	SelectCharacterForward:  key.NewBinding(key.WithKeys("shift+right")),
+	SelectCharacterBackward: key.NewBinding(key.WithKeys("shift+left")),
+	Undo:                    key.NewBinding(key.WithKeys("ctrl+z")),
+	Redo:                    key.NewBinding(key.WithKeys("alt+z")),
+	HistoryPrevious:         key.NewBinding(key.WithKeys("up")),
+	HistoryNext:             key.NewBinding(key.WithKeys("down")),
+	UppercaseWordForward:    key.NewBinding(key.WithKeys("alt+u")),
+	LowercaseWordForward:    key.NewBinding(key.WithKeys("alt+l")),
+	CapitalizeWordForward:   key.NewBinding(key.WithKeys("alt+c")),
+	MenuNextSuggestion:      key.NewBinding(key.WithKeys("tab")),
+	MenuPrevSuggestion:      key.NewBinding(key.WithKeys("shift+tab")),
+	MenuAcceptSuggestion:    key.NewBinding(key.WithKeys("enter")),
+	Yank:                    key.NewBinding(key.WithKeys("ctrl+y")),
+	SelectAll:               key.NewBinding(key.WithKeys("alt+a")),
+	ToggleReveal:            key.NewBinding(key.WithKeys("ctrl+r")),
+	Submit:                  key.NewBinding(key.WithKeys("enter")),
+	Redraw:                  key.NewBinding(key.WithKeys("ctrl+l")),
 }
 
 // Model is the Bubble Tea model for this text input element.
@@ -93,9 +221,20 @@ type Model struct {
 	EchoCharacter rune
 	Cursor        cursor.Model
 
+	// EchoRevealLast briefly shows the last typed character in EchoPassword
+	// mode, like password fields on phones. It's masked after
+	// EchoRevealDuration, or on the next key press.
+	EchoRevealLast     bool
+	EchoRevealDuration time.Duration
+
 	// Deprecated: use [cursor.BlinkSpeed] instead.
 	BlinkSpeed time.Duration
 
+	// BlinkPauseOnType keeps the cursor solid while typing: edits that don't
+	// move the cursor, like deleting forward, show it and restart its blink
+	// too. Cursor movements always do. It's on by default.
+	BlinkPauseOnType bool
+
 	// Styles. These will be applied as inline styles.
 	//
 	// For an introduction to styling with Lip Gloss see:
@@ -104,6 +243,7 @@ type Model struct {
 	TextStyle        lipgloss.Style
 	PlaceholderStyle lipgloss.Style
 	CompletionStyle  lipgloss.Style
+	SelectionStyle   lipgloss.Style
 
 	// Deprecated: use Cursor.Style instead.
 	CursorStyle lipgloss.Style
@@ -112,11 +252,40 @@ type Model struct {
 	// accept. If 0 or less, there's no limit.
 	CharLimit int
 
+	// Suffix is rendered after the value, styled with SuffixStyle, for things
+	// like a unit label. When Width is set, the suffix takes up part of it.
+	Suffix      string
+	SuffixStyle lipgloss.Style
+
+	// LimitIndicator is rendered after the input, styled with
+	// LimitIndicatorStyle, when typed or pasted characters are rejected
+	// because of CharLimit. It's shown until the next key press.
+	LimitIndicator      string
+	LimitIndicatorStyle lipgloss.Style
+
 	// Width is the maximum number of characters that can be displayed at once.
 	// It essentially treats the text field like a horizontally scrolling
 	// viewport. If 0 or less this setting is ignored.
 	Width int
 
+	// Truncate sets how a value wider than Width is shown while the input is
+	// blurred, which suits read-only or summary displays. While focused the
+	// value always scrolls so that it can be edited.
+	Truncate TruncateMode
+
+	// PasteNewlineBehavior sets whether newlines in pasted text are replaced
+	// with spaces or submit the input with a SubmitMsg.
+	PasteNewlineBehavior PasteNewlineBehavior
+
+	// SubmitOnEnter makes the Submit key send a SubmitMsg with the value, so
+	// that parents don't have to intercept it themselves. While the
+	// suggestion menu is open the key accepts the selected suggestion instead.
+	SubmitOnEnter bool
+
+	// MaxUndo is the maximum number of edits that can be undone. If 0 or
+	// less, undo is disabled.
+	MaxUndo int
+
 	// KeyMap encodes the keybindings recognized by the widget.
 	KeyMap KeyMap
 
@@ -130,6 +299,43 @@ type Model struct {
 	// Cursor position.
 	pos int
 
+	// Selection state. When active, the selection spans from the anchor to
+	// the cursor position.
+	selAnchor int
+	selActive bool
+
+	// Edit history for undo and redo.
+	undoStack []snapshot
+	redoStack []snapshot
+
+	// Previously submitted values. historyIndex is the entry currently being
+	// shown; when it equals the length of the history the user is editing
+	// historyDraft, the value before history navigation began.
+	history      []string
+	historyIndex int
+	historyDraft string
+
+	// Text deleted with the kill commands, most recent last, for yanking.
+	// lastKill is the direction of the previous key's kill, if it was one.
+	killRing []string
+	lastKill killDirection
+
+	// revealed shows the value as is despite the echo mode.
+	revealed bool
+
+	// limitHit is set when characters were rejected because of CharLimit.
+	limitHit bool
+
+	// The ID of this Model as it relates to other inputs, and the tag of the
+	// maskLastMsg we're expecting to receive.
+	id      int
+	lastTag int
+
+	// lastShown is set while the last typed character, at lastPos, is shown
+	// because of EchoRevealLast.
+	lastShown bool
+	lastPos   int
+
 	// Used to emulate a viewport when width is set and the content is
 	// overflowing.
 	offset      int
@@ -141,17 +347,47 @@ type Model struct {
 	// input is considered valid.
 	Validate ValidateFunc
 
+	// CharFilter reports whether a rune typed or pasted by the user should be
+	// inserted. Runes for which it returns false are silently dropped. If the
+	// function is not defined, all runes are accepted.
+	CharFilter func(rune) bool
+
+	// WordSeparators lists the characters, besides whitespace, that separate
+	// words for word-wise navigation and deletion, such as "/_-." for editing
+	// paths. By default, only whitespace separates words.
+	WordSeparators string
+
+	// OnChange is called with the new value whenever Update changes the
+	// value, such as when the user types, deletes or pastes text. Setting the
+	// value programmatically with SetValue or Reset doesn't call it. If the
+	// function is not defined, nothing is called.
+	OnChange func(string)
+
+	// Mask formats the value as it's typed. Each '#' in the mask accepts a
+	// digit and the other characters are inserted automatically, e.g.
+	// "(###) ###-####" for a phone number. RawValue returns just the digits.
+	Mask string
+
 	// rune sanitizer for input.
 	rsan runeutil.Sanitizer
 
 	// Should the input suggest to complete
 	ShowSuggestions bool
 
+	// ShowSuggestionMenu opens a menu of the matching suggestions when there
+	// are several, rendered by SuggestionsView. While it's open the menu keys
+	// cycle through the suggestions and accept the selected one.
+	// ShowSuggestions must be set as well.
+	ShowSuggestionMenu      bool
+	SuggestionStyle         lipgloss.Style
+	SelectedSuggestionStyle lipgloss.Style
+
 	// suggestions is a list of suggestions that may be used to complete the
 	// input.
 	suggestions            [][]rune
 	matchedSuggestions     [][]rune
 	currentSuggestionIndex int
+	menuOpen               bool
 }
 
 // New creates a new model with default settings.
@@ -160,16 +396,26 @@ func New() Model {
 		Prompt:           "> ",
 		EchoCharacter:    '*',
 		CharLimit:        0,
+		MaxUndo:          defaultMaxUndo,
 		PlaceholderStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
 		ShowSuggestions:  false,
 		CompletionStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		SelectionStyle:   lipgloss.NewStyle().Reverse(true),
 		Cursor:           cursor.New(),
+		BlinkPauseOnType: true,
 		KeyMap:           DefaultKeyMap,
 
+		EchoRevealDuration: time.Second,
+
+		LimitIndicatorStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		SuggestionStyle:         lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		SelectedSuggestionStyle: lipgloss.NewStyle().Reverse(true),
+
 		suggestions: [][]rune{},
 		value:       nil,
 		focus:       false,
 		pos:         0,
+		id:          nextID(),
 	}
 }
 
@@ -178,13 +424,20 @@ func New() Model {
 // Deprecated: Use [New] instead.
 var NewModel = New
 
-// SetValue sets the value of the text input.
+// SetValue sets the value of the text input and places the cursor at the end.
 func (m *Model) SetValue(s string) {
 	// Clean up any special characters in the input provided by the
 	// caller. This avoids bugs due to e.g. tab characters and whatnot.
 	runes := m.san().Sanitize([]rune(s))
 	err := m.validate(runes)
 	m.setValueInternal(runes, err)
+	if m.Mask != "" {
+		m.applyMask()
+	}
+	m.CursorEnd()
+	m.lastKill = killNone // kills from the old value aren't combined.
+	m.limitHit = false
+	m.lastShown = false
 }
 
 func (m *Model) setValueInternal(runes []rune, err error) {
@@ -203,16 +456,71 @@ func (m *Model) setValueInternal(runes []rune, err error) {
 	m.handleOverflow()
 }
 
+// InsertString inserts s at the cursor and moves the cursor past it, as if it
+// had been typed. CharLimit and CharFilter apply.
+func (m *Model) InsertString(s string) {
+	m.clearSelection()
+	m.insertRunesFromUserInput([]rune(s))
+	if m.Mask != "" {
+		m.applyMask()
+	}
+	m.updateSuggestions()
+	m.handleOverflow()
+}
+
+// InsertRune inserts r at the cursor and moves the cursor past it, as if it
+// had been typed. CharLimit and CharFilter apply.
+func (m *Model) InsertRune(r rune) {
+	m.InsertString(string(r))
+}
+
 // Value returns the value of the text input.
 func (m Model) Value() string {
 	return string(m.value)
 }
 
+// ValueTrimmed returns the value of the text input with leading and trailing
+// whitespace removed. The value itself is left as is.
+func (m Model) ValueTrimmed() string {
+	return strings.TrimSpace(string(m.value))
+}
+
+// RawValue returns the digits typed into a masked input, without the mask's
+// literal characters. Without a Mask it's the same as Value.
+func (m Model) RawValue() string {
+	if m.Mask == "" {
+		return m.Value()
+	}
+	return string(m.maskDigits(m.value))
+}
+
+// RuneCount returns the number of runes in the value. This is the same count
+// CharLimit is measured against.
+func (m Model) RuneCount() int {
+	return len(m.value)
+}
+
+// WordCount returns the number of whitespace-separated words in the value.
+func (m Model) WordCount() int {
+	return len(strings.Fields(string(m.value)))
+}
+
 // Position returns the cursor position.
 func (m Model) Position() int {
 	return m.pos
 }
 
+// CursorColumn returns the display column of the cursor within the output of
+// View, taking the prompt and horizontal scrolling into account. This is
+// useful for positioning overlays such as a completion popup.
+func (m Model) CursorColumn() int {
+	col := lipgloss.Width(m.PromptStyle.Render(m.Prompt))
+	if m.pos > m.offset {
+		col += uniseg.StringWidth(m.echoTransform(string(m.value[m.offset:m.pos])))
+	}
+	return col
+}
+
 // SetCursor moves the cursor to the given position. If the position is
 // out of bounds the cursor will be moved to the start or end accordingly.
 func (m *Model) SetCursor(pos int) {
@@ -242,19 +550,120 @@ func (m *Model) Focus() tea.Cmd {
 	return m.Cursor.Focus()
 }
 
+// SetBlinkOff sets whether the cursor is in the off phase of its blink, in
+// which it's hidden. Together with BlinkOff it lets a parent drive the cursors
+// of several inputs from a single timer, keeping them in step.
+func (m *Model) SetBlinkOff(off bool) {
+	m.Cursor.Blink = off
+}
+
+// BlinkOff returns whether the cursor is in the off phase of its blink, in
+// which it's hidden. It's false for a new input, whose cursor is shown.
+func (m Model) BlinkOff() bool {
+	return m.Cursor.Blink
+}
+
 // Blur removes the focus state on the model.  When the model is blurred it can
 // not receive keyboard input and the cursor will be hidden.
 func (m *Model) Blur() {
 	m.focus = false
+	m.menuOpen = false
+	m.revealed = false
+	m.limitHit = false
+	m.lastShown = false
 	m.Cursor.Blur()
 }
 
-// Reset sets the input to its default state with no input.
+// Reveal temporarily shows the value of a password or hidden input as is,
+// until Conceal is called or the input is blurred.
+func (m *Model) Reveal() {
+	m.revealed = true
+}
+
+// Conceal hides the value of a password or hidden input again after Reveal.
+func (m *Model) Conceal() {
+	m.revealed = false
+}
+
+// Revealed returns whether the value is shown as is despite the echo mode.
+func (m Model) Revealed() bool {
+	return m.revealed
+}
+
+// Reset sets the input to its default state with no input, and shows the
+// cursor at the start.
 func (m *Model) Reset() {
 	m.value = nil
+	m.Cursor.Blink = false
+	m.menuOpen = false
+	m.limitHit = false
+	m.lastShown = false
+	m.clearSelection()
 	m.SetCursor(0)
 }
 
+// SelectedText returns the currently selected text, if any.
+func (m Model) SelectedText() string {
+	start, end := m.selectionRange()
+	return string(m.value[start:end])
+}
+
+// selectionRange returns the start and end positions of the selection. If
+// nothing is selected start and end are equal.
+func (m Model) selectionRange() (start, end int) {
+	if !m.selActive {
+		return 0, 0
+	}
+	start = clamp(m.selAnchor, 0, len(m.value))
+	end = clamp(m.pos, 0, len(m.value))
+	if start > end {
+		start, end = end, start
+	}
+	return start, end
+}
+
+// selectTo extends the selection by moving the cursor to the given position.
+func (m *Model) selectTo(pos int) {
+	if !m.selActive {
+		m.selAnchor = m.pos
+		m.selActive = true
+	}
+	m.SetCursor(pos)
+	if m.pos == m.selAnchor {
+		m.clearSelection()
+	}
+}
+
+// SelectAll selects the entire value, so that typing replaces it.
+func (m *Model) SelectAll() {
+	if len(m.value) == 0 {
+		return
+	}
+	m.selAnchor = 0
+	m.selActive = true
+	m.SetCursor(len(m.value))
+}
+
+// clearSelection deselects any selected text.
+func (m *Model) clearSelection() {
+	m.selAnchor = 0
+	m.selActive = false
+}
+
+// deleteSelection deletes the selected text, if any, and reports whether
+// anything was deleted.
+func (m *Model) deleteSelection() bool {
+	start, end := m.selectionRange()
+	m.clearSelection()
+	if start == end {
+		return false
+	}
+	m.value = append(m.value[:start], m.value[end:]...)
+	m.Err = m.validate(m.value)
+	m.SetCursor(start)
+	return true
+}
+
 // SetSuggestions sets the suggestions for the input.
 func (m *Model) SetSuggestions(suggestions []string) {
 	m.suggestions = make([][]rune, len(suggestions))
@@ -265,6 +674,98 @@ func (m *Model) SetSuggestions(suggestions []string) {
 	m.updateSuggestions()
 }
 
+// snapshot returns the current state of the input for the edit history.
+func (m Model) snapshot() snapshot {
+	return snapshot{value: string(m.value), pos: m.pos}
+}
+
+// pushUndo records the state of the input prior to an edit so it can be
+// undone. Nothing is recorded if the value did not change.
+func (m *Model) pushUndo(prev snapshot) {
+	if m.MaxUndo <= 0 || prev.value == string(m.value) {
+		return
+	}
+	m.undoStack = append(m.undoStack, prev)
+	if len(m.undoStack) > m.MaxUndo {
+		m.undoStack = m.undoStack[len(m.undoStack)-m.MaxUndo:]
+	}
+	m.redoStack = nil
+}
+
+// undo reverts the last edit.
+func (m *Model) undo() {
+	if len(m.undoStack) == 0 {
+		return
+	}
+	m.redoStack = append(m.redoStack, m.snapshot())
+	prev := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.restore(prev)
+}
+
+// redo reapplies the last undone edit.
+func (m *Model) redo() {
+	if len(m.redoStack) == 0 {
+		return
+	}
+	m.undoStack = append(m.undoStack, m.snapshot())
+	next := m.redoStack[len(m.redoStack)-1]
+	m.redoStack = m.redoStack[:len(m.redoStack)-1]
+	m.restore(next)
+}
+
+// restore sets the input to a state from its edit history.
+func (m *Model) restore(s snapshot) {
+	m.value = []rune(s.value)
+	m.clearSelection()
+	m.Err = m.validate(m.value)
+	m.SetCursor(s.pos)
+}
+
+// PushHistory adds a submitted value to the input's history so that it can be
+// recalled with the HistoryPrevious and HistoryNext keys. Empty values are
+// ignored.
+func (m *Model) PushHistory(s string) {
+	if s != "" {
+		m.history = append(m.history, s)
+	}
+	m.historyIndex = len(m.history)
+	m.historyDraft = ""
+}
+
+// historyPrevious replaces the value with the previous history entry.
+func (m *Model) historyPrevious() {
+	if m.historyIndex <= 0 {
+		return
+	}
+	if m.historyIndex == len(m.history) {
+		m.historyDraft = string(m.value)
+	}
+	m.historyIndex--
+	m.setHistoryValue(m.history[m.historyIndex])
+}
+
+// historyNext replaces the value with the next history entry, or with the
+// value that was being edited before navigating the history.
+func (m *Model) historyNext() {
+	if m.historyIndex >= len(m.history) {
+		return
+	}
+	m.historyIndex++
+	if m.historyIndex == len(m.history) {
+		m.setHistoryValue(m.historyDraft)
+		return
+	}
+	m.setHistoryValue(m.history[m.historyIndex])
+}
+
+// setHistoryValue sets the value to a history entry and moves the cursor to
+// the end.
+func (m *Model) setHistoryValue(s string) {
+	m.SetValue(s)
+	m.CursorEnd()
+}
+
 // rsan initializes or retrieves the rune sanitizer.
 func (m *Model) san() runeutil.Sanitizer {
 	if m.rsan == nil {
@@ -282,9 +783,20 @@ func (m *Model) insertRunesFromUserInput(v []rune) {
 	// whatnot.
 	paste := m.san().Sanitize(v)
 
+	if m.CharFilter != nil {
+		filtered := paste[:0]
+		for _, r := range paste {
+			if m.CharFilter(r) {
+				filtered = append(filtered, r)
+			}
+		}
+		paste = filtered
+	}
+
 	var availSpace int
 	if m.CharLimit > 0 {
 		availSpace = m.CharLimit - len(m.value)
+		m.limitHit = len(paste) > availSpace
 
 		// If the char limit's been reached, cancel.
 		if availSpace <= 0 {
@@ -325,7 +837,8 @@ func (m *Model) insertRunesFromUserInput(v []rune) {
 // If a max width is defined, perform some logic to treat the visible area
 // as a horizontally scrolling viewport.
 func (m *Model) handleOverflow() {
-	if m.Width <= 0 || uniseg.StringWidth(string(m.value)) <= m.Width {
+	width := m.valueWidth()
+	if width <= 0 || m.displayWidth(m.value) <= width {
 		m.offset = 0
 		m.offsetRight = len(m.value)
 		return
@@ -334,37 +847,153 @@ func (m *Model) handleOverflow() {
 	// Correct right offset if we've deleted characters
 	m.offsetRight = min(m.offsetRight, len(m.value))
 
+	// The window is moved by grapheme cluster, so that composite characters
+	// are measured and shown as a whole.
+	bounds := graphemeBounds(m.value)
+	clusterWidth := func(k int) int {
+		return m.displayWidth(m.value[bounds[k]:bounds[k+1]])
+	}
+
 	if m.pos < m.offset {
 		m.offset = m.pos
 
 		w := 0
-		i := 0
-		runes := m.value[m.offset:]
-
-		for i < len(runes) && w <= m.Width {
-			w += rw.RuneWidth(runes[i])
-			if w <= m.Width+1 {
-				i++
+		k := sort.SearchInts(bounds, m.offset)
+		for ; k < len(bounds)-1 && w <= width; k++ {
+			w += clusterWidth(k)
+			if w > width+1 {
+				break
 			}
 		}
 
-		m.offsetRight = m.offset + i
+		m.offsetRight = bounds[k]
 	} else if m.pos >= m.offsetRight {
 		m.offsetRight = m.pos
 
 		w := 0
-		runes := m.value[:m.offsetRight]
-		i := len(runes) - 1
+		k := sort.SearchInts(bounds, m.offsetRight)
+		for k > 0 && w+clusterWidth(k-1) <= width {
+			w += clusterWidth(k - 1)
+			k--
+		}
 
-		for i > 0 && w < m.Width {
-			w += rw.RuneWidth(runes[i])
-			if w <= m.Width {
-				i--
-			}
+		m.offset = bounds[k]
+	}
+}
+
+// nextGrapheme returns the position after the grapheme cluster at pos, so
+// that composite characters like flags and emoji ZWJ sequences are stepped
+// over and deleted as one.
+func (m Model) nextGrapheme(pos int) int {
+	if pos >= len(m.value) {
+		return len(m.value)
+	}
+	return pos + graphemeLen(m.value[pos:])
+}
+
+// prevGrapheme returns the position of the grapheme cluster before pos.
+func (m Model) prevGrapheme(pos int) int {
+	var (
+		rest    = string(m.value)
+		state   = -1
+		i, prev int
+		cluster string
+	)
+	for rest != "" && i < pos {
+		prev = i
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		i += utf8.RuneCountInString(cluster)
+	}
+	return prev
+}
+
+// graphemeBounds returns the positions at which the grapheme clusters of
+// runes start, followed by len(runes), segmenting them in one pass.
+func graphemeBounds(runes []rune) []int {
+	var (
+		bounds  []int
+		rest    = string(runes)
+		state   = -1
+		i       int
+		cluster string
+	)
+	for rest != "" {
+		bounds = append(bounds, i)
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		i += utf8.RuneCountInString(cluster)
+	}
+	return append(bounds, len(runes))
+}
+
+// graphemeLen returns the number of runes in the first grapheme cluster of
+// runes.
+func graphemeLen(runes []rune) int {
+	if len(runes) == 0 {
+		return 0
+	}
+	cluster, _, _, _ := uniseg.FirstGraphemeClusterInString(string(runes), -1)
+	return max(1, utf8.RuneCountInString(cluster))
+}
+
+// valueWidth returns the number of columns available to the value, which is
+// Width less the suffix. If Width is 0 or less, there's no limit.
+func (m Model) valueWidth() int {
+	if m.Width <= 0 {
+		return m.Width
+	}
+	return max(1, m.Width-lipgloss.Width(m.SuffixStyle.Render(m.Suffix)))
+}
+
+// suffixView renders the suffix.
+func (m Model) suffixView() string {
+	if m.Suffix == "" {
+		return ""
+	}
+	return m.SuffixStyle.Inline(true).Render(m.Suffix)
+}
+
+// displayWidth returns the number of columns the given runes of the value take
+// up in the view, which depends on the echo mode and the width of the echo
+// character.
+func (m Model) displayWidth(runes []rune) int {
+	return uniseg.StringWidth(m.echoTransform(string(runes)))
+}
+
+// kill runs the deletion del and saves the deleted text to the kill ring.
+// Consecutive kills in the same direction are combined into one entry. Text
+// in masked inputs isn't saved.
+func (m *Model) kill(del func(), dir killDirection) {
+	before := append([]rune(nil), m.value...)
+	del()
+
+	// Deletions leave the cursor at the start of the deleted text.
+	n := len(before) - len(m.value)
+	if n <= 0 || m.EchoMode != EchoNormal {
+		return
+	}
+	text := string(before[m.pos : m.pos+n])
+
+	switch last := len(m.killRing) - 1; {
+	case last >= 0 && m.lastKill == dir && dir == killBackward:
+		m.killRing[last] = text + m.killRing[last]
+	case last >= 0 && m.lastKill == dir:
+		m.killRing[last] += text
+	default:
+		m.killRing = append(m.killRing, text)
+		if len(m.killRing) > maxKillRing {
+			m.killRing = m.killRing[1:]
 		}
+	}
+	m.lastKill = dir
+}
 
-		m.offset = m.offsetRight - (len(runes) - 1 - i)
+// yank inserts the most recently killed text at the cursor.
+func (m *Model) yank() {
+	if len(m.killRing) == 0 {
+		return
 	}
+	m.deleteSelection()
+	m.insertRunesFromUserInput([]rune(m.killRing[len(m.killRing)-1]))
 }
 
 // deleteBeforeCursor deletes all text before the cursor.
@@ -401,7 +1030,7 @@ func (m *Model) deleteWordBackward() {
 	oldPos := m.pos //nolint:ifshort
 
 	m.SetCursor(m.pos - 1)
-	for unicode.IsSpace(m.value[m.pos]) {
+	for m.isWordSeparator(m.value[m.pos]) {
 		if m.pos <= 0 {
 			break
 		}
@@ -410,7 +1039,7 @@ func (m *Model) deleteWordBackward() {
 	}
 
 	for m.pos > 0 {
-		if !unicode.IsSpace(m.value[m.pos]) {
+		if !m.isWordSeparator(m.value[m.pos]) {
 			m.SetCursor(m.pos - 1)
 		} else {
 			if m.pos > 0 {
@@ -444,17 +1073,13 @@ func (m *Model) deleteWordForward() {
 
 	oldPos := m.pos
 	m.SetCursor(m.pos + 1)
-	for unicode.IsSpace(m.value[m.pos]) {
+	for m.pos < len(m.value) && m.isWordSeparator(m.value[m.pos]) {
 		// ignore series of whitespace after cursor
 		m.SetCursor(m.pos + 1)
-
-		if m.pos >= len(m.value) {
-			break
-		}
 	}
 
 	for m.pos < len(m.value) {
-		if !unicode.IsSpace(m.value[m.pos]) {
+		if !m.isWordSeparator(m.value[m.pos]) {
 			m.SetCursor(m.pos + 1)
 		} else {
 			break
@@ -471,6 +1096,11 @@ func (m *Model) deleteWordForward() {
 	m.SetCursor(oldPos)
 }
 
+// isWordSeparator reports whether r separates words.
+func (m Model) isWordSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(m.WordSeparators, r)
+}
+
 // wordBackward moves the cursor one word to the left. If input is masked, move
 // input to the start so as not to reveal word breaks in the masked input.
 func (m *Model) wordBackward() {
@@ -485,7 +1115,7 @@ func (m *Model) wordBackward() {
 
 	i := m.pos - 1
 	for i >= 0 {
-		if unicode.IsSpace(m.value[i]) {
+		if m.isWordSeparator(m.value[i]) {
 			m.SetCursor(m.pos - 1)
 			i--
 		} else {
@@ -494,7 +1124,7 @@ func (m *Model) wordBackward() {
 	}
 
 	for i >= 0 {
-		if !unicode.IsSpace(m.value[i]) {
+		if !m.isWordSeparator(m.value[i]) {
 			m.SetCursor(m.pos - 1)
 			i--
 		} else {
@@ -517,7 +1147,7 @@ func (m *Model) wordForward() {
 
 	i := m.pos
 	for i < len(m.value) {
-		if unicode.IsSpace(m.value[i]) {
+		if m.isWordSeparator(m.value[i]) {
 			m.SetCursor(m.pos + 1)
 			i++
 		} else {
@@ -526,7 +1156,7 @@ func (m *Model) wordForward() {
 	}
 
 	for i < len(m.value) {
-		if !unicode.IsSpace(m.value[i]) {
+		if !m.isWordSeparator(m.value[i]) {
 			m.SetCursor(m.pos + 1)
 			i++
 		} else {
@@ -535,7 +1165,52 @@ func (m *Model) wordForward() {
 	}
 }
 
+// doWordForward moves the cursor to the end of the next word, calling fn for
+// each rune of the word along the way.
+func (m *Model) doWordForward(fn func(charIdx int, pos int)) {
+	// Skip spaces forward.
+	for m.pos < len(m.value) && m.isWordSeparator(m.value[m.pos]) {
+		m.SetCursor(m.pos + 1)
+	}
+
+	charIdx := 0
+	for m.pos < len(m.value) && !m.isWordSeparator(m.value[m.pos]) {
+		fn(charIdx, m.pos)
+		m.SetCursor(m.pos + 1)
+		charIdx++
+	}
+	m.Err = m.validate(m.value)
+}
+
+// uppercaseForward changes the word to the right to uppercase.
+func (m *Model) uppercaseForward() {
+	m.doWordForward(func(_ int, i int) {
+		m.value[i] = unicode.ToUpper(m.value[i])
+	})
+}
+
+// lowercaseForward changes the word to the right to lowercase.
+func (m *Model) lowercaseForward() {
+	m.doWordForward(func(_ int, i int) {
+		m.value[i] = unicode.ToLower(m.value[i])
+	})
+}
+
+// capitalizeForward changes the word to the right to title case.
+func (m *Model) capitalizeForward() {
+	m.doWordForward(func(charIdx int, i int) {
+		if charIdx == 0 {
+			m.value[i] = unicode.ToTitle(m.value[i])
+		} else {
+			m.value[i] = unicode.ToLower(m.value[i])
+		}
+	})
+}
+
 func (m Model) echoTransform(v string) string {
+	if m.revealed {
+		return v
+	}
 	switch m.EchoMode {
 	case EchoPassword:
 		return strings.Repeat(string(m.EchoCharacter), uniseg.StringWidth(v))
@@ -548,6 +1223,94 @@ func (m Model) echoTransform(v string) string {
 	}
 }
 
+// echoRunes is like echoTransform for the given runes of the value, starting
+// at index start, except that the last typed character is shown as is while
+// EchoRevealLast shows it.
+func (m Model) echoRunes(runes []rune, start int) string {
+	i := m.lastPos - start
+	if !m.lastShown || m.EchoMode != EchoPassword || i < 0 || i >= len(runes) {
+		return m.echoTransform(string(runes))
+	}
+	return m.echoTransform(string(runes[:i])) + string(runes[i]) + m.echoTransform(string(runes[i+1:]))
+}
+
+// maskDigits returns the digits of v that fill the mask's digit slots. v is
+// matched against the mask as far as possible, so that digits survive edits
+// that leave v misaligned with it, like deleting a literal character.
+func (m Model) maskDigits(v []rune) []rune {
+	var (
+		mask   = []rune(m.Mask)
+		digits []rune
+		j      int
+	)
+	for _, r := range v {
+		if j < len(mask) && mask[j] != maskDigit && r == mask[j] {
+			j++
+			continue
+		}
+		if !unicode.IsDigit(r) {
+			continue
+		}
+		for j < len(mask) && mask[j] != maskDigit {
+			j++
+		}
+		if j == len(mask) {
+			break
+		}
+		digits = append(digits, r)
+		j++
+	}
+	return digits
+}
+
+// formatMask fills the mask's digit slots with digits. Literal characters
+// are included up to the last filled slot.
+func (m Model) formatMask(digits []rune) (v []rune) {
+	for _, r := range m.Mask {
+		switch {
+		case len(digits) == 0:
+			return v
+		case r == maskDigit:
+			v = append(v, digits[0])
+			digits = digits[1:]
+		default:
+			v = append(v, r)
+		}
+	}
+	return v
+}
+
+// applyMask reformats the value with the mask, keeping the cursor after the
+// same digit.
+func (m *Model) applyMask() {
+	before := len(m.maskDigits(m.value[:m.pos]))
+	m.value = m.formatMask(m.maskDigits(m.value))
+	m.Err = m.validate(m.value)
+
+	// Place the cursor after the digit it was after, or after the leading
+	// literals when there was none.
+	pos := 0
+	for i, r := range []rune(m.Mask) {
+		if i >= len(m.value) || (r == maskDigit && before == 0) {
+			break
+		}
+		pos = i + 1
+		if r == maskDigit {
+			before--
+		}
+	}
+	m.SetCursor(pos)
+}
+
+// skipMaskLiteralsBackward moves the cursor back over the mask's literal
+// characters, so that backspace deletes the digit before them.
+func (m *Model) skipMaskLiteralsBackward() {
+	mask := []rune(m.Mask)
+	for m.pos > 0 && m.pos <= len(mask) && mask[m.pos-1] != maskDigit {
+		m.pos--
+	}
+}
+
 // Update is the Bubble Tea update loop.
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	if !m.focus {
@@ -556,78 +1319,199 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	// Need to check for completion before, because key is configurable and might be double assigned
 	keyMsg, ok := msg.(tea.KeyMsg)
-	if ok && key.Matches(keyMsg, m.KeyMap.AcceptSuggestion) {
-		if m.canAcceptSuggestion() {
-			m.value = append(m.value, m.matchedSuggestions[m.currentSuggestionIndex][len(m.value):]...)
-			m.CursorEnd()
-		}
+
+	// Remember the value so that changes can be reported.
+	oldValue := string(m.value)
+
+	// Remember the state prior to any edits so they can be undone.
+	var prev snapshot
+	if ok {
+		prev = m.snapshot()
+	}
+
+	// Moving forward at the end of the input accepts the suggestion as well.
+	// The suggestion menu takes precedence while it's open.
+	atEnd := m.pos == len(m.value)
+	accepted := false
+	if ok && !m.menuOpen && (key.Matches(keyMsg, m.KeyMap.AcceptSuggestion) || atEnd && key.Matches(keyMsg, m.KeyMap.CharacterForward)) {
+		accepted = m.acceptSuggestion()
 	}
 
 	// Let's remember where the position of the cursor currently is so that if
 	// the cursor position changes, we can reset the blink.
 	oldPos := m.pos //nolint
 
+	// Whether to submit the input once the message is handled.
+	submit := false
+
+	// Whether the last typed character is to be shown once the message is
+	// handled.
+	showLast := false
+
+	// The limit indicator and the last typed character are shown until the
+	// next key press or paste.
+	if _, paste := msg.(pasteMsg); ok || paste {
+		m.limitHit = false
+		m.lastShown = false
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch {
+		case m.menuOpen && key.Matches(msg, m.KeyMap.MenuNextSuggestion):
+			m.nextSuggestion()
+		case m.menuOpen && key.Matches(msg, m.KeyMap.MenuPrevSuggestion):
+			m.previousSuggestion()
+		case m.menuOpen && key.Matches(msg, m.KeyMap.MenuAcceptSuggestion):
+			accepted = m.acceptSuggestion()
+		case m.SubmitOnEnter && key.Matches(msg, m.KeyMap.Submit):
+			submit = true
 		case key.Matches(msg, m.KeyMap.DeleteWordBackward):
-			m.deleteWordBackward()
+			m.kill(m.deleteWordBackward, killBackward)
 		case key.Matches(msg, m.KeyMap.DeleteCharacterBackward):
 			m.Err = nil
-			if len(m.value) > 0 {
-				m.value = append(m.value[:max(0, m.pos-1)], m.value[m.pos:]...)
+			if m.deleteSelection() {
+				break
+			}
+			if m.Mask != "" {
+				m.skipMaskLiteralsBackward()
+			}
+			if len(m.value) > 0 && m.pos > 0 {
+				start := m.prevGrapheme(m.pos)
+				m.value = append(m.value[:start], m.value[m.pos:]...)
 				m.Err = m.validate(m.value)
-				if m.pos > 0 {
-					m.SetCursor(m.pos - 1)
-				}
+				m.SetCursor(start)
 			}
 		case key.Matches(msg, m.KeyMap.WordBackward):
 			m.wordBackward()
 		case key.Matches(msg, m.KeyMap.CharacterBackward):
 			if m.pos > 0 {
-				m.SetCursor(m.pos - 1)
+				m.SetCursor(m.prevGrapheme(m.pos))
 			}
 		case key.Matches(msg, m.KeyMap.WordForward):
 			m.wordForward()
 		case key.Matches(msg, m.KeyMap.CharacterForward):
 			if m.pos < len(m.value) {
-				m.SetCursor(m.pos + 1)
+				m.SetCursor(m.nextGrapheme(m.pos))
 			}
 		case key.Matches(msg, m.KeyMap.LineStart):
 			m.CursorStart()
 		case key.Matches(msg, m.KeyMap.DeleteCharacterForward):
+			if m.deleteSelection() {
+				break
+			}
 			if len(m.value) > 0 && m.pos < len(m.value) {
-				m.value = append(m.value[:m.pos], m.value[m.pos+1:]...)
+				m.value = append(m.value[:m.pos], m.value[m.nextGrapheme(m.pos):]...)
 				m.Err = m.validate(m.value)
 			}
 		case key.Matches(msg, m.KeyMap.LineEnd):
 			m.CursorEnd()
 		case key.Matches(msg, m.KeyMap.DeleteAfterCursor):
-			m.deleteAfterCursor()
+			m.kill(m.deleteAfterCursor, killForward)
 		case key.Matches(msg, m.KeyMap.DeleteBeforeCursor):
-			m.deleteBeforeCursor()
+			m.kill(m.deleteBeforeCursor, killBackward)
 		case key.Matches(msg, m.KeyMap.Paste):
 			return m, Paste
+		case key.Matches(msg, m.KeyMap.Redraw):
+			return m, redraw
 		case key.Matches(msg, m.KeyMap.DeleteWordForward):
-			m.deleteWordForward()
+			m.kill(m.deleteWordForward, killForward)
+		case key.Matches(msg, m.KeyMap.Yank):
+			m.yank()
+		case key.Matches(msg, m.KeyMap.ToggleReveal):
+			m.revealed = !m.revealed
+		case key.Matches(msg, m.KeyMap.HistoryPrevious) && !m.canAcceptSuggestion():
+			m.historyPrevious()
+		case key.Matches(msg, m.KeyMap.HistoryNext) && !m.canAcceptSuggestion():
+			m.historyNext()
 		case key.Matches(msg, m.KeyMap.NextSuggestion):
 			m.nextSuggestion()
 		case key.Matches(msg, m.KeyMap.PrevSuggestion):
 			m.previousSuggestion()
+		case key.Matches(msg, m.KeyMap.SelectCharacterForward):
+			m.selectTo(m.nextGrapheme(m.pos))
+		case key.Matches(msg, m.KeyMap.SelectCharacterBackward):
+			m.selectTo(m.prevGrapheme(m.pos))
+		case key.Matches(msg, m.KeyMap.SelectAll):
+			m.SelectAll()
+		case key.Matches(msg, m.KeyMap.UppercaseWordForward):
+			m.uppercaseForward()
+		case key.Matches(msg, m.KeyMap.LowercaseWordForward):
+			m.lowercaseForward()
+		case key.Matches(msg, m.KeyMap.CapitalizeWordForward):
+			m.capitalizeForward()
+		case key.Matches(msg, m.KeyMap.Undo):
+			m.undo()
+		case key.Matches(msg, m.KeyMap.Redo):
+			m.redo()
 		default:
-			// Input one or more regular characters.
-			m.insertRunesFromUserInput(msg.Runes)
+			// Input one or more regular characters, replacing the selection.
+			runes := msg.Runes
+			if msg.Paste {
+				runes, submit = m.pasteRunes(runes)
+			}
+			if len(runes) > 0 {
+				m.deleteSelection()
+			}
+			n := len(m.value)
+			m.insertRunesFromUserInput(runes)
+			showLast = m.EchoRevealLast && !msg.Paste && len(m.value) > n
+		}
+
+		// Any other key than a kill key ends a run of kills.
+		if !key.Matches(msg, m.KeyMap.DeleteWordBackward, m.KeyMap.DeleteWordForward, m.KeyMap.DeleteAfterCursor, m.KeyMap.DeleteBeforeCursor) {
+			m.lastKill = killNone
+		}
+
+		// Any other key than a selection key deselects.
+		if !key.Matches(msg, m.KeyMap.SelectCharacterForward, m.KeyMap.SelectCharacterBackward, m.KeyMap.SelectAll) {
+			m.clearSelection()
+		}
+
+		if !key.Matches(msg, m.KeyMap.Undo, m.KeyMap.Redo) {
+			m.pushUndo(prev)
+		}
+
+		// Editing the value stops history navigation.
+		if !key.Matches(msg, m.KeyMap.HistoryPrevious, m.KeyMap.HistoryNext) && prev.value != string(m.value) {
+			m.historyIndex = len(m.history)
 		}
 
 		// Check again if can be completed
 		// because value might be something that does not match the completion prefix
 		m.updateSuggestions()
 
+		// Typing opens the menu when the value is ambiguous, and accepting a
+		// suggestion closes it.
+		if accepted {
+			m.menuOpen = false
+		} else if prev.value != string(m.value) {
+			m.menuOpen = m.ShowSuggestions && m.ShowSuggestionMenu && len(m.matchedSuggestions) > 1
+		}
+
 	case pasteMsg:
-		m.insertRunesFromUserInput([]rune(msg))
+		var runes []rune
+		runes, submit = m.pasteRunes([]rune(msg))
+		prev := m.snapshot()
+		m.deleteSelection()
+		m.insertRunesFromUserInput(runes)
+		m.pushUndo(prev)
 
 	case pasteErrMsg:
 		m.Err = msg
+
+	case maskLastMsg:
+		if msg.id == m.id && msg.tag == m.lastTag {
+			m.lastShown = false
+		}
+	}
+
+	if m.Mask != "" && string(m.value) != oldValue {
+		m.applyMask()
+	}
+
+	if m.OnChange != nil && string(m.value) != oldValue {
+		m.OnChange(string(m.value))
 	}
 
 	var cmds []tea.Cmd
@@ -636,15 +1520,57 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	m.Cursor, cmd = m.Cursor.Update(msg)
 	cmds = append(cmds, cmd)
 
-	if oldPos != m.pos && m.Cursor.Mode() == cursor.CursorBlink {
+	reset := oldPos != m.pos || m.BlinkPauseOnType && string(m.value) != oldValue
+	if reset && m.Cursor.Mode() == cursor.CursorBlink {
 		m.Cursor.Blink = false
 		cmds = append(cmds, m.Cursor.BlinkCmd())
 	}
 
+	if submit {
+		cmds = append(cmds, m.submit())
+	}
+
+	if showLast {
+		m.lastShown = true
+		m.lastPos = m.pos - 1
+		m.lastTag++
+		cmds = append(cmds, m.maskLast())
+	}
+
 	m.handleOverflow()
 	return m, tea.Batch(cmds...)
 }
 
+// pasteRunes returns the part of the pasted runes v to insert and whether the
+// paste submits the input, according to PasteNewlineBehavior.
+func (m Model) pasteRunes(v []rune) ([]rune, bool) {
+	if m.PasteNewlineBehavior == PasteNewlineSubmit {
+		for i, r := range v {
+			if r == '\n' || r == '\r' {
+				return v[:i], true
+			}
+		}
+	}
+	return v, false
+}
+
+// submit returns a command sending a SubmitMsg with the current value.
+func (m Model) submit() tea.Cmd {
+	value := m.Value()
+	return func() tea.Msg {
+		return SubmitMsg{Value: value}
+	}
+}
+
+// maskLast returns a command that masks the last typed character again after
+// EchoRevealDuration.
+func (m Model) maskLast() tea.Cmd {
+	id, tag := m.id, m.lastTag
+	return tea.Tick(m.EchoRevealDuration, func(time.Time) tea.Msg {
+		return maskLastMsg{id: id, tag: tag}
+	})
+}
+
 // View renders the textinput in its current state.
 func (m Model) View() string {
 	// Placeholder text
@@ -652,18 +1578,24 @@ func (m Model) View() string {
 		return m.placeholderView()
 	}
 
+	if v, ok := m.truncatedView(); ok {
+		return m.PromptStyle.Render(m.Prompt) + v + m.suffixView()
+	}
+
 	styleText := m.TextStyle.Inline(true).Render
 
 	value := m.value[m.offset:m.offsetRight]
 	pos := max(0, m.pos-m.offset)
-	v := styleText(m.echoTransform(string(value[:pos])))
+	v := m.textView(value[:pos], m.offset)
 
 	if pos < len(value) {
-		char := m.echoTransform(string(value[pos]))
+		// The cursor covers the whole grapheme cluster under it.
+		end := pos + graphemeLen(value[pos:])
+		char := m.echoRunes(value[pos:end], m.offset+pos)
 		m.Cursor.SetChar(char)
-		v += m.Cursor.View()                                   // cursor and text under it
-		v += styleText(m.echoTransform(string(value[pos+1:]))) // text after cursor
-		v += m.completionView(0)                               // suggested completion
+		v += m.Cursor.View()                       // cursor and text under it
+		v += m.textView(value[end:], m.offset+end) // text after cursor
+		v += m.completionView(0)                   // suggested completion
 	} else {
 		if m.canAcceptSuggestion() {
 			suggestion := m.matchedSuggestions[m.currentSuggestionIndex]
@@ -684,20 +1616,78 @@ func (m Model) View() string {
 
 	// If a max width and background color were set fill the empty spaces with
 	// the background color.
-	valWidth := uniseg.StringWidth(string(value))
-	if m.Width > 0 && valWidth <= m.Width {
-		padding := max(0, m.Width-valWidth)
-		if valWidth+padding <= m.Width && pos < len(value) {
+	valWidth := m.displayWidth(value)
+	if width := m.valueWidth(); width > 0 && valWidth <= width {
+		padding := max(0, width-valWidth)
+		if valWidth+padding <= width && pos < len(value) {
 			padding++
 		}
 		v += styleText(strings.Repeat(" ", padding))
 	}
 
+	v += m.suffixView()
+	if m.limitHit && m.LimitIndicator != "" {
+		v += m.LimitIndicatorStyle.Inline(true).Render(m.LimitIndicator)
+	}
+
 	return m.PromptStyle.Render(m.Prompt) + v
 }
 
+// textView renders the given runes of the value, highlighting those that are
+// selected. start is the position of the first rune within the value.
+func (m Model) textView(runes []rune, start int) string {
+	styleText := m.TextStyle.Inline(true).Render
+
+	selStart, selEnd := m.selectionRange()
+	from := clamp(selStart-start, 0, len(runes))
+	to := clamp(selEnd-start, 0, len(runes))
+	if from == to {
+		return styleText(m.echoRunes(runes, start))
+	}
+
+	styleSelection := m.SelectionStyle.Inline(true).Render
+	return styleText(m.echoRunes(runes[:from], start)) +
+		styleSelection(m.echoRunes(runes[from:to], start+from)) +
+		styleText(m.echoRunes(runes[to:], start+to))
+}
+
+// truncatedView renders the value truncated to Width according to Truncate.
+// It reports false when the value isn't to be truncated.
+func (m Model) truncatedView() (string, bool) {
+	width := m.valueWidth()
+	if m.Truncate == TruncateNone || m.focus || width <= 0 {
+		return "", false
+	}
+	v := m.echoTransform(string(m.value))
+	if uniseg.StringWidth(v) <= width {
+		return "", false
+	}
+
+	const ellipsis = "…"
+	switch m.Truncate {
+	case TruncateMiddle:
+		runes := []rune(v)
+		// The ellipsis takes up one column, and the start of the value gets
+		// the odd one out of the rest.
+		head := width / 2
+		tail := width - 1 - head
+
+		// Collect the tail from the end, by width.
+		i, w := len(runes), 0
+		for i > 0 && w+rw.RuneWidth(runes[i-1]) <= tail {
+			w += rw.RuneWidth(runes[i-1])
+			i--
+		}
+		v = rw.Truncate(string(runes[:i]), head, "") + ellipsis + string(runes[i:])
+	default:
+		v = rw.Truncate(v, width, ellipsis)
+	}
+	return m.TextStyle.Inline(true).Render(v), true
+}
+
 // placeholderView returns the prompt and placeholder view, if any.
 func (m Model) placeholderView() string {
+	width := m.valueWidth()
 	var (
 		v     string
 		p     = []rune(m.Placeholder)
@@ -709,15 +1699,15 @@ func (m Model) placeholderView() string {
 	v += m.Cursor.View()
 
 	// If the entire placeholder is already set and no padding is needed, finish
-	if m.Width < 1 && len(p) <= 1 {
-		return m.PromptStyle.Render(m.Prompt) + v
+	if width < 1 && len(p) <= 1 {
+		return m.PromptStyle.Render(m.Prompt) + v + m.suffixView()
 	}
 
 	// If Width is set then size placeholder accordingly
-	if m.Width > 0 {
+	if width > 0 {
 		// available width is width - len + cursor offset of 1
 		minWidth := lipgloss.Width(m.Placeholder)
-		availWidth := m.Width - minWidth + 1
+		availWidth := width - minWidth + 1
 
 		// if width < len, 'subtract'(add) number to len and dont add padding
 		if availWidth < 0 {
@@ -732,7 +1722,7 @@ func (m Model) placeholderView() string {
 		v += style(string(p[1:]))
 	}
 
-	return m.PromptStyle.Render(m.Prompt) + v
+	return m.PromptStyle.Render(m.Prompt) + v + m.suffixView()
 }
 
 // Blink is a command used to initialize cursor blinking.
@@ -801,7 +1791,7 @@ func (m *Model) SetCursorMode(mode CursorMode) tea.Cmd {
 func (m Model) completionView(offset int) string {
 	var (
 		value = m.value
-		style = m.PlaceholderStyle.Inline(true).Render
+		style = m.CompletionStyle.Inline(true).Render
 	)
 
 	if m.canAcceptSuggestion() {
@@ -832,10 +1822,68 @@ func (m *Model) CurrentSuggestion() string {
 	return string(m.matchedSuggestions[m.currentSuggestionIndex])
 }
 
+// MatchedSuggestions returns the suggestions matching the current value.
+func (m Model) MatchedSuggestions() []string {
+	suggestions := make([]string, len(m.matchedSuggestions))
+	for i, s := range m.matchedSuggestions {
+		suggestions[i] = string(s)
+	}
+
+	return suggestions
+}
+
+// CurrentSuggestionIndex returns the index of the currently selected
+// suggestion within MatchedSuggestions.
+func (m Model) CurrentSuggestionIndex() int {
+	return m.currentSuggestionIndex
+}
+
+// SuggestionMenuOpen returns whether the suggestion menu is open.
+func (m Model) SuggestionMenuOpen() bool {
+	return m.menuOpen
+}
+
+// SuggestionsView renders the suggestion menu, one suggestion per line and
+// aligned with the text after the prompt. It's empty while the menu is
+// closed.
+func (m Model) SuggestionsView() string {
+	if !m.menuOpen {
+		return ""
+	}
+
+	indent := strings.Repeat(" ", uniseg.StringWidth(m.Prompt))
+	lines := make([]string, len(m.matchedSuggestions))
+	for i, s := range m.matchedSuggestions {
+		style := m.SuggestionStyle
+		if i == m.currentSuggestionIndex {
+			style = m.SelectedSuggestionStyle
+		}
+		lines[i] = indent + style.Inline(true).Render(string(s))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// acceptSuggestion completes the value with the current suggestion. It
+// returns whether there was one to accept.
+func (m *Model) acceptSuggestion() bool {
+	if !m.canAcceptSuggestion() {
+		return false
+	}
+	m.value = append(m.value, m.matchedSuggestions[m.currentSuggestionIndex][len(m.value):]...)
+	m.CursorEnd()
+	return true
+}
+
 // canAcceptSuggestion returns whether there is an acceptable suggestion to
-// autocomplete the current value.
+// autocomplete the current value. The value must still be a prefix of the
+// selected suggestion, in case it changed since the suggestions were matched.
 func (m *Model) canAcceptSuggestion() bool {
-	return len(m.matchedSuggestions) > 0
+	if m.currentSuggestionIndex < 0 || m.currentSuggestionIndex >= len(m.matchedSuggestions) {
+		return false
+	}
+	suggestion := m.matchedSuggestions[m.currentSuggestionIndex]
+	return len(m.value) <= len(suggestion) &&
+		strings.EqualFold(string(suggestion[:len(m.value)]), string(m.value))
 }
 
 // updateSuggestions refreshes the list of matching suggestions.
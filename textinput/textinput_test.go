@@ -1,7 +1,20 @@
 package textinput
 
 import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
 )
 
 func Test_CurrentSuggestion(t *testing.T) {
@@ -30,3 +43,1727 @@ func Test_CurrentSuggestion(t *testing.T) {
 		t.Fatalf("Error: expected first suggestion but was %s", suggestion)
 	}
 }
+
+func Test_NewModel(t *testing.T) {
+	// Each model gets its own ID.
+	a, b := New(), NewModel()
+	if a.id == b.id {
+		t.Fatal("Error: expected models to have distinct IDs")
+	}
+	b.id = a.id
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("Error: expected NewModel to return the same model as New")
+	}
+
+	textinput := NewModel()
+	if textinput.Value() != "" || textinput.Position() != 0 || textinput.Focused() {
+		t.Fatal("Error: expected an empty, blurred model")
+	}
+	if cmd := textinput.Focus(); cmd == nil || textinput.Cursor.BlinkSpeed != cursor.DefaultBlinkSpeed {
+		t.Fatal("Error: expected the cursor to blink at the default speed once focused")
+	}
+}
+
+func Test_RuneAwareEditing(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+
+	textinput = sendString(textinput, "café")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if v := textinput.Value(); v != "caé" {
+		t.Fatalf("Error: expected %q but was %q", "caé", v)
+	}
+
+	textinput.Reset()
+	textinput = sendString(textinput, "日本語😀")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	textinput = sendString(textinput, "x")
+	if v := textinput.Value(); v != "日本x語" {
+		t.Fatalf("Error: expected %q but was %q", "日本x語", v)
+	}
+	if pos := textinput.Position(); pos != 3 {
+		t.Fatalf("Error: expected cursor at 3 but was %d", pos)
+	}
+}
+
+func Test_LineEndAppends(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+
+	textinput = sendString(textinput, "hello")
+	textinput.CursorStart()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if pos := textinput.Position(); pos != 5 {
+		t.Fatalf("Error: expected cursor at 5 but was %d", pos)
+	}
+
+	textinput = sendString(textinput, "!")
+	if v := textinput.Value(); v != "hello!" {
+		t.Fatalf("Error: expected %q but was %q", "hello!", v)
+	}
+}
+
+func Test_BackspaceAtStart(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+
+	textinput = sendString(textinput, "abc")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyHome})
+	for i := 0; i < 3; i++ {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+
+	if v := textinput.Value(); v != "abc" {
+		t.Fatalf("Error: expected %q but was %q", "abc", v)
+	}
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was %d", pos)
+	}
+}
+
+func Test_EchoMode(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.EchoMode = EchoPassword
+	textinput.Focus()
+
+	textinput = sendString(textinput, "secret")
+	if v := textinput.Value(); v != "secret" {
+		t.Fatalf("Error: expected value %q but was %q", "secret", v)
+	}
+
+	view := ansi.Strip(textinput.View())
+	if strings.Contains(view, "secret") {
+		t.Fatalf("Error: expected masked view but was %q", view)
+	}
+	if masked := strings.Count(view, "*"); masked != 6 {
+		t.Fatalf("Error: expected 6 mask characters but got %d in %q", masked, view)
+	}
+
+	textinput.EchoMode = EchoNone
+	if view := strings.TrimSpace(ansi.Strip(textinput.View())); view != "" {
+		t.Fatalf("Error: expected empty view but was %q", view)
+	}
+}
+
+func Test_CharLimit(t *testing.T) {
+	textinput := New()
+	textinput.CharLimit = 5
+	textinput.Focus()
+
+	textinput = sendString(textinput, "ä1ö2ü3")
+	if v := textinput.Value(); v != "ä1ö2ü" {
+		t.Fatalf("Error: expected %q but was %q", "ä1ö2ü", v)
+	}
+}
+
+func Test_HorizontalScrolling(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Width = 5
+	textinput.Focus()
+
+	// Cursor at the end: the tail of the value is visible, followed by the
+	// cursor cell.
+	textinput = sendString(textinput, "abcdefghij")
+	if view := ansi.Strip(textinput.View()); view != "fghij " {
+		t.Fatalf("Error: expected %q but was %q", "fghij ", view)
+	}
+
+	// Cursor in the middle: the window only scrolls once the cursor moves
+	// past its left edge.
+	for i := 0; i < 4; i++ {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	if view := ansi.Strip(textinput.View()); view != "fghij " {
+		t.Fatalf("Error: expected %q but was %q", "fghij ", view)
+	}
+	for i := 0; i < 4; i++ {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	if view := ansi.Strip(textinput.View()); view != "cdefgh" {
+		t.Fatalf("Error: expected %q but was %q", "cdefgh", view)
+	}
+
+	// Scrolling all the way back to the left.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if view := ansi.Strip(textinput.View()); view != "abcdef" {
+		t.Fatalf("Error: expected %q but was %q", "abcdef", view)
+	}
+}
+
+func Test_HorizontalScrollingWide(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Width = 6
+	textinput.Focus()
+
+	// Wide characters take up two columns each, so only three fit.
+	textinput = sendString(textinput, "日本語中文")
+	if view := ansi.Strip(textinput.View()); view != "語中文 " {
+		t.Fatalf("Error: expected %q but was %q", "語中文 ", view)
+	}
+
+	// However the window scrolls over mixed widths, the view keeps the same
+	// width: Width columns plus the cursor cell.
+	textinput.Reset()
+	textinput = sendString(textinput, "ab日本cd語e")
+	views := []string{"cd語e  ", "cd語e  ", "cd語e  ", "cd語e  ", "cd語e  ", "本cd語e", "日本cd ", "b日本cd", "ab日本c"}
+	for i, want := range views {
+		if i > 0 {
+			textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		}
+		view := textinput.View()
+		if ansi.Strip(view) != want {
+			t.Fatalf("Error: expected %q at position %d but was %q", want, textinput.Position(), ansi.Strip(view))
+		}
+		if w := ansi.StringWidth(view); w != 7 {
+			t.Fatalf("Error: expected the view to be 7 columns wide but was %d", w)
+		}
+	}
+}
+
+func Test_HorizontalScrollingGraphemes(t *testing.T) {
+	const (
+		family = "👨‍👩‍👧"
+		flag   = "🇯🇵"
+	)
+
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Width = 6
+	textinput.Focus()
+
+	// A cluster takes up the two columns it's shown in.
+	textinput = sendString(textinput, family+"abcdefg")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if view := ansi.Strip(textinput.View()); view != family+"abcde" {
+		t.Fatalf("Error: expected %q but was %q", family+"abcde", view)
+	}
+
+	// Scrolling over clusters keeps them whole and the view the same width.
+	textinput.SetValue("ab" + flag + "cd" + family + "e")
+	textinput.CursorEnd()
+	views := []string{
+		"cd" + family + "e  ",
+		"cd" + family + "e  ",
+		"cd" + family + "e  ",
+		"cd" + family + "e  ",
+		"cd" + family + "e  ",
+		flag + "cd" + family + "e",
+		"b" + flag + "cd" + family,
+		"ab" + flag + "cd ",
+	}
+	for i, want := range views {
+		if i > 0 {
+			textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		}
+		view := textinput.View()
+		if ansi.Strip(view) != want {
+			t.Fatalf("Error: expected %q at position %d but was %q", want, textinput.Position(), ansi.Strip(view))
+		}
+		if w := ansi.StringWidth(view); w != 7 {
+			t.Fatalf("Error: expected the view to be 7 columns wide but was %d", w)
+		}
+	}
+}
+
+func Test_HorizontalScrollingWideEcho(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Width = 6
+	textinput.EchoMode = EchoPassword
+	textinput.EchoCharacter = '＊'
+	textinput.Focus()
+
+	// Each masked rune takes up the two columns of the echo character, so
+	// only three fit.
+	textinput = sendString(textinput, "abcde")
+	for i, col := range []int{6, 4, 2, 0, 0} {
+		if i > 0 {
+			textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		}
+		view := textinput.View()
+		if v := ansi.Strip(view); v != "＊＊＊ " {
+			t.Fatalf("Error: expected %q at position %d but was %q", "＊＊＊ ", textinput.Position(), v)
+		}
+		if w := ansi.StringWidth(view); w != 7 {
+			t.Fatalf("Error: expected the view to be 7 columns wide but was %d", w)
+		}
+		if c := textinput.CursorColumn(); c != col {
+			t.Fatalf("Error: expected the cursor in column %d at position %d but was %d", col, textinput.Position(), c)
+		}
+	}
+}
+
+func Test_Placeholder(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Placeholder = "Name"
+	textinput.Focus()
+
+	if view := ansi.Strip(textinput.View()); view != "Name" {
+		t.Fatalf("Error: expected placeholder %q but was %q", "Name", view)
+	}
+
+	textinput = sendString(textinput, "J")
+	if view := ansi.Strip(textinput.View()); strings.Contains(view, "Name") {
+		t.Fatalf("Error: expected placeholder to be replaced but was %q", view)
+	}
+}
+
+func Test_WordNavigation(t *testing.T) {
+	// Terminals send either alt or ctrl with the arrow keys for word jumps.
+	for _, keys := range []struct {
+		name        string
+		left, right tea.KeyMsg
+	}{
+		{"alt", tea.KeyMsg{Type: tea.KeyLeft, Alt: true}, tea.KeyMsg{Type: tea.KeyRight, Alt: true}},
+		{"ctrl", tea.KeyMsg{Type: tea.KeyCtrlLeft}, tea.KeyMsg{Type: tea.KeyCtrlRight}},
+	} {
+		textinput := New()
+		textinput.Focus()
+		textinput.SetValue("cd   ~/some/path,  now")
+
+		for _, want := range []int{19, 5, 0, 0} {
+			textinput, _ = textinput.Update(keys.left)
+			if pos := textinput.Position(); pos != want {
+				t.Fatalf("Error: expected %s+left to move the cursor to %d but was %d", keys.name, want, pos)
+			}
+		}
+		for _, want := range []int{2, 17, 22, 22} {
+			textinput, _ = textinput.Update(keys.right)
+			if pos := textinput.Position(); pos != want {
+				t.Fatalf("Error: expected %s+right to move the cursor to %d but was %d", keys.name, want, pos)
+			}
+		}
+	}
+}
+
+func Test_DeleteWordBackward(t *testing.T) {
+	ctrlW := tea.KeyMsg{Type: tea.KeyCtrlW}
+
+	textinput := New()
+	textinput.Focus()
+	textinput = sendString(textinput, "one two  three")
+
+	// Cursor at the end of a word.
+	for _, want := range []string{"one two  ", "one ", ""} {
+		textinput, _ = textinput.Update(ctrlW)
+		if v := textinput.Value(); v != want {
+			t.Fatalf("Error: expected %q but was %q", want, v)
+		}
+		if pos := textinput.Position(); pos != len([]rune(want)) {
+			t.Fatalf("Error: expected cursor at %d but was %d", len([]rune(want)), pos)
+		}
+	}
+
+	// Cursor in the middle of a word.
+	textinput.SetValue("one two")
+	textinput.SetCursor(5)
+	textinput, _ = textinput.Update(ctrlW)
+	if v := textinput.Value(); v != "one wo" {
+		t.Fatalf("Error: expected %q but was %q", "one wo", v)
+	}
+	if pos := textinput.Position(); pos != 4 {
+		t.Fatalf("Error: expected cursor at 4 but was %d", pos)
+	}
+}
+
+func Test_DeleteWordForward(t *testing.T) {
+	altD := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}, Alt: true}
+
+	textinput := New()
+	textinput.Focus()
+	textinput.SetValue("one two three")
+
+	// Cursor in the middle of a word.
+	textinput.SetCursor(5)
+	textinput, _ = textinput.Update(altD)
+	if v := textinput.Value(); v != "one t three" {
+		t.Fatalf("Error: expected %q but was %q", "one t three", v)
+	}
+	if pos := textinput.Position(); pos != 5 {
+		t.Fatalf("Error: expected cursor at 5 but was %d", pos)
+	}
+
+	// Cursor before a separator deletes it along with the next word.
+	textinput, _ = textinput.Update(altD)
+	if v := textinput.Value(); v != "one t" {
+		t.Fatalf("Error: expected %q but was %q", "one t", v)
+	}
+
+	// Cursor at the end of the line.
+	textinput, _ = textinput.Update(altD)
+	if v := textinput.Value(); v != "one t" {
+		t.Fatalf("Error: expected nothing to be deleted at the end but was %q", v)
+	}
+
+	// Deleted text goes to the kill ring, runes and all.
+	textinput.SetValue("日本 語")
+	textinput.CursorStart()
+	textinput, _ = textinput.Update(altD)
+	if v := textinput.Value(); v != " 語" {
+		t.Fatalf("Error: expected %q but was %q", " 語", v)
+	}
+	textinput.CursorEnd()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if v := textinput.Value(); v != " 語日本" {
+		t.Fatalf("Error: expected the deleted word to be yanked, got %q", v)
+	}
+
+	// Cursor on the last rune of the value.
+	for _, tc := range []struct {
+		value    string
+		pos      int
+		expected string
+	}{
+		{"one two", 6, "one tw"},
+		{"one two", 4, "one "},
+		{"é", 0, ""},
+	} {
+		textinput.SetValue(tc.value)
+		textinput.SetCursor(tc.pos)
+		textinput, _ = textinput.Update(altD)
+		if v := textinput.Value(); v != tc.expected {
+			t.Fatalf("Error: expected %q but was %q", tc.expected, v)
+		}
+		if pos := textinput.Position(); pos != tc.pos {
+			t.Fatalf("Error: expected cursor at %d but was %d", tc.pos, pos)
+		}
+	}
+}
+
+func Test_DeleteBeforeCursor(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput.SetValue("hello world")
+	textinput.SetCursor(6)
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	if v := textinput.Value(); v != "world" {
+		t.Fatalf("Error: expected %q but was %q", "world", v)
+	}
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was %d", pos)
+	}
+}
+
+func Test_FocusBlur(t *testing.T) {
+	textinput := New()
+	if textinput.Focused() {
+		t.Fatal("Error: expected new textinput to be blurred")
+	}
+
+	textinput = sendString(textinput, "ignored")
+	if v := textinput.Value(); v != "" {
+		t.Fatalf("Error: expected keystrokes to be dropped while blurred but value was %q", v)
+	}
+
+	textinput.Focus()
+	textinput = sendString(textinput, "typed")
+	if v := textinput.Value(); v != "typed" {
+		t.Fatalf("Error: expected %q but was %q", "typed", v)
+	}
+
+	textinput.Blur()
+	textinput = sendString(textinput, "!")
+	if v := textinput.Value(); v != "typed" {
+		t.Fatalf("Error: expected %q but was %q", "typed", v)
+	}
+}
+
+func Test_SetValueAndReset(t *testing.T) {
+	textinput := New()
+	textinput.SetValue("prefilled ✓")
+	if pos := textinput.Position(); pos != 11 {
+		t.Fatalf("Error: expected cursor at 11 but was %d", pos)
+	}
+
+	textinput.Reset()
+	if v := textinput.Value(); v != "" {
+		t.Fatalf("Error: expected empty value but was %q", v)
+	}
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was %d", pos)
+	}
+
+	// The same goes for an input with a value and the cursor in the middle.
+	textinput.Focus()
+	textinput.SetValue("hello world")
+	textinput.SetCursor(2)
+	textinput.SetValue("bye")
+	if pos := textinput.Position(); pos != 3 {
+		t.Fatalf("Error: expected cursor at 3 but was %d", pos)
+	}
+
+	textinput.SetCursor(1)
+	textinput.Cursor.Blink = true
+	textinput.Reset()
+	if v := textinput.Value(); v != "" {
+		t.Fatalf("Error: expected empty value but was %q", v)
+	}
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was %d", pos)
+	}
+	if textinput.Cursor.Blink {
+		t.Fatal("Error: expected the cursor to be shown after a reset")
+	}
+}
+
+func Test_SetValueClampsCursor(t *testing.T) {
+	textinput := New()
+	textinput.Width = 5
+	textinput.Focus()
+	textinput = sendString(textinput, "a long value that scrolls")
+
+	textinput.SetValue("")
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was %d", pos)
+	}
+	if view := textinput.View(); !strings.HasPrefix(view, "> ") {
+		t.Fatalf("Error: expected the empty input to render but got %q", view)
+	}
+
+	textinput = sendString(textinput, "a long value that scrolls")
+	textinput.SetValue("ab")
+	if pos := textinput.Position(); pos != 2 {
+		t.Fatalf("Error: expected cursor at 2 but was %d", pos)
+	}
+	textinput.View()
+}
+
+func Test_CursorStartEnd(t *testing.T) {
+	textinput := New()
+	textinput.SetValue("über")
+	textinput.SetCursor(2)
+
+	textinput.CursorStart()
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected cursor at 0 but was %d", pos)
+	}
+
+	textinput.CursorEnd()
+	if pos := textinput.Position(); pos != 4 {
+		t.Fatalf("Error: expected cursor at 4 but was %d", pos)
+	}
+}
+
+func Test_HomeEnd(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput.SetValue("über")
+	textinput.SetCursor(2)
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if pos := textinput.Position(); pos != 0 {
+		t.Fatalf("Error: expected home to move the cursor to 0 but was %d", pos)
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if pos := textinput.Position(); pos != 4 {
+		t.Fatalf("Error: expected end to move the cursor to 4 but was %d", pos)
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	errNotDigits := errors.New("only digits are allowed")
+
+	textinput := New()
+	textinput.Validate = func(s string) error {
+		for _, r := range s {
+			if !unicode.IsDigit(r) {
+				return errNotDigits
+			}
+		}
+		return nil
+	}
+	textinput.Focus()
+
+	textinput = sendString(textinput, "12")
+	if textinput.Err != nil {
+		t.Fatalf("Error: expected no error but got %v", textinput.Err)
+	}
+
+	textinput = sendString(textinput, "a")
+	if textinput.Err != errNotDigits {
+		t.Fatalf("Error: expected %v but got %v", errNotDigits, textinput.Err)
+	}
+	if v := textinput.Value(); v != "12a" {
+		t.Fatalf("Error: expected invalid keystroke to be accepted but value was %q", v)
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if textinput.Err != nil {
+		t.Fatalf("Error: expected error to clear after correction but got %v", textinput.Err)
+	}
+}
+
+func Test_CharFilter(t *testing.T) {
+	textinput := New()
+	textinput.CharFilter = unicode.IsDigit
+	textinput.Focus()
+
+	textinput = sendString(textinput, "4a2b")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x1-0"), Paste: true})
+	if v := textinput.Value(); v != "4210" {
+		t.Fatalf("Error: expected %q but was %q", "4210", v)
+	}
+	if pos := textinput.Position(); pos != 4 {
+		t.Fatalf("Error: expected cursor at 4 but was %d", pos)
+	}
+}
+
+func Test_Paste(t *testing.T) {
+	textinput := New()
+	textinput.CharLimit = 12
+	textinput.Focus()
+	textinput.SetValue("[]")
+	textinput.SetCursor(1)
+
+	textinput, _ = textinput.Update(pasteMsg("first\nsecond\nthird"))
+	if v := textinput.Value(); v != "[first seco]" {
+		t.Fatalf("Error: expected %q but was %q", "[first seco]", v)
+	}
+	if pos := textinput.Position(); pos != 11 {
+		t.Fatalf("Error: expected cursor at 11 but was %d", pos)
+	}
+}
+
+func Test_Selection(t *testing.T) {
+	shiftLeft := tea.KeyMsg{Type: tea.KeyShiftLeft}
+	shiftRight := tea.KeyMsg{Type: tea.KeyShiftRight}
+
+	textinput := New()
+	textinput.Focus()
+	textinput.SetValue("hello world")
+
+	// Extend the selection leftwards from the end.
+	for i := 0; i < 5; i++ {
+		textinput, _ = textinput.Update(shiftLeft)
+	}
+	if sel := textinput.SelectedText(); sel != "world" {
+		t.Fatalf("Error: expected selection %q but was %q", "world", sel)
+	}
+
+	// Shrinking it back past the anchor deselects.
+	for i := 0; i < 5; i++ {
+		textinput, _ = textinput.Update(shiftRight)
+	}
+	if sel := textinput.SelectedText(); sel != "" {
+		t.Fatalf("Error: expected no selection but was %q", sel)
+	}
+
+	// Typing replaces the selection.
+	for i := 0; i < 5; i++ {
+		textinput, _ = textinput.Update(shiftLeft)
+	}
+	textinput = sendString(textinput, "there")
+	if v := textinput.Value(); v != "hello there" {
+		t.Fatalf("Error: expected %q but was %q", "hello there", v)
+	}
+
+	// Backspace deletes the selection.
+	textinput.CursorStart()
+	for i := 0; i < 6; i++ {
+		textinput, _ = textinput.Update(shiftRight)
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if v := textinput.Value(); v != "there" {
+		t.Fatalf("Error: expected %q but was %q", "there", v)
+	}
+
+	// A plain arrow key clears the selection.
+	textinput, _ = textinput.Update(shiftRight)
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if sel := textinput.SelectedText(); sel != "" {
+		t.Fatalf("Error: expected no selection but was %q", sel)
+	}
+	if pos := textinput.Position(); pos != 2 {
+		t.Fatalf("Error: expected cursor at 2 but was %d", pos)
+	}
+}
+
+func Test_UndoRedo(t *testing.T) {
+	undo := tea.KeyMsg{Type: tea.KeyCtrlZ}
+	redo := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}, Alt: true}
+
+	// Redo is on alt+z, since ctrl+y yanks and ctrl+shift+z can't be told
+	// apart from ctrl+z in most terminals.
+	if key.Matches(tea.KeyMsg{Type: tea.KeyCtrlY}, DefaultKeyMap.Redo) || !key.Matches(redo, DefaultKeyMap.Redo) {
+		t.Fatal("Error: expected redo to be bound to alt+z and not ctrl+y")
+	}
+
+	textinput := New()
+	textinput.Focus()
+	textinput = sendString(textinput, "abc")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+
+	for _, want := range []string{"abc", "ab", "a", "", ""} {
+		textinput, _ = textinput.Update(undo)
+		if v := textinput.Value(); v != want {
+			t.Fatalf("Error: expected %q after undo but was %q", want, v)
+		}
+		if pos := textinput.Position(); pos != len(want) {
+			t.Fatalf("Error: expected cursor at %d after undo but was %d", len(want), pos)
+		}
+	}
+
+	for _, want := range []string{"a", "ab", "abc", "", ""} {
+		textinput, _ = textinput.Update(redo)
+		if v := textinput.Value(); v != want {
+			t.Fatalf("Error: expected %q after redo but was %q", want, v)
+		}
+	}
+
+	// A new edit discards the redo history.
+	textinput, _ = textinput.Update(undo)
+	textinput = sendString(textinput, "d")
+	textinput, _ = textinput.Update(redo)
+	if v := textinput.Value(); v != "abcd" {
+		t.Fatalf("Error: expected %q but was %q", "abcd", v)
+	}
+}
+
+func Test_MaxUndo(t *testing.T) {
+	textinput := New()
+	textinput.MaxUndo = 2
+	textinput.Focus()
+	textinput = sendString(textinput, "abcd")
+
+	for i := 0; i < 4; i++ {
+		textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	}
+	if v := textinput.Value(); v != "ab" {
+		t.Fatalf("Error: expected %q but was %q", "ab", v)
+	}
+}
+
+func Test_History(t *testing.T) {
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	textinput := New()
+	textinput.Focus()
+	textinput.PushHistory("first")
+	textinput.PushHistory("second")
+	textinput.PushHistory("third")
+	textinput = sendString(textinput, "draft")
+
+	textinput, _ = textinput.Update(up)
+	textinput, _ = textinput.Update(up)
+	if v := textinput.Value(); v != "second" {
+		t.Fatalf("Error: expected %q but was %q", "second", v)
+	}
+	if textinput.historyIndex != 1 {
+		t.Fatalf("Error: expected history index 1 but was %d", textinput.historyIndex)
+	}
+	if pos := textinput.Position(); pos != 6 {
+		t.Fatalf("Error: expected cursor at 6 but was %d", pos)
+	}
+
+	textinput, _ = textinput.Update(down)
+	textinput, _ = textinput.Update(down)
+	if v := textinput.Value(); v != "draft" {
+		t.Fatalf("Error: expected draft %q to be restored but was %q", "draft", v)
+	}
+
+	// Typing resets the history index.
+	textinput, _ = textinput.Update(up)
+	textinput = sendString(textinput, "!")
+	if textinput.historyIndex != 3 {
+		t.Fatalf("Error: expected history index 3 but was %d", textinput.historyIndex)
+	}
+}
+
+func Test_CursorBlink(t *testing.T) {
+	textinput := New()
+	textinput.Cursor.BlinkSpeed = time.Millisecond
+
+	cmd := textinput.Focus()
+	if cmd == nil {
+		t.Fatal("Error: expected focusing to schedule a blink")
+	}
+	if textinput.Cursor.Blink {
+		t.Fatal("Error: expected the cursor to be visible after focusing")
+	}
+
+	textinput, cmd = textinput.Update(cmd())
+	if !textinput.Cursor.Blink {
+		t.Fatal("Error: expected the blink message to toggle the cursor")
+	}
+	if cmd == nil {
+		t.Fatal("Error: expected the next blink to be scheduled")
+	}
+
+	textinput.Blur()
+	textinput.Cursor.SetMode(cursor.CursorStatic)
+	if cmd := textinput.Focus(); cmd != nil {
+		t.Fatal("Error: expected no blink to be scheduled for a static cursor")
+	}
+}
+
+func Test_BlinkPauseOnType(t *testing.T) {
+	textinput := New()
+	textinput.Cursor.BlinkSpeed = time.Millisecond
+	blink := textinput.Focus()()
+	textinput.SetValue("abc")
+	textinput.CursorStart()
+
+	// An edit shows the cursor and restarts the blink.
+	textinput.SetBlinkOff(true)
+	textinput, cmd := textinput.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if textinput.BlinkOff() {
+		t.Fatal("Error: expected the edit to show the cursor")
+	}
+	if cmd == nil {
+		t.Fatal("Error: expected the edit to schedule a blink")
+	}
+	textinput, _ = textinput.Update(blink)
+	if textinput.BlinkOff() {
+		t.Fatal("Error: expected the blink scheduled before the edit to be ignored")
+	}
+
+	// Without it, edits in place leave the blink alone.
+	textinput.BlinkPauseOnType = false
+	textinput.SetBlinkOff(true)
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if !textinput.BlinkOff() {
+		t.Fatal("Error: expected the cursor to keep blinking after an edit in place")
+	}
+
+	// Moving the cursor restarts the blink either way.
+	textinput, cmd = textinput.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if textinput.BlinkOff() {
+		t.Fatal("Error: expected moving the cursor to show it")
+	}
+	if cmd == nil {
+		t.Fatal("Error: expected moving the cursor to schedule a blink")
+	}
+}
+
+func Test_ChangeWordCase(t *testing.T) {
+	altKey := func(r rune) tea.Msg {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true}
+	}
+
+	textinput := New()
+	textinput.Focus()
+	textinput.SetValue("ärger  über éCOLE")
+	textinput.CursorStart()
+
+	textinput, _ = textinput.Update(altKey('u'))
+	if v := textinput.Value(); v != "ÄRGER  über éCOLE" {
+		t.Fatalf("Error: expected %q but was %q", "ÄRGER  über éCOLE", v)
+	}
+	if pos := textinput.Position(); pos != 5 {
+		t.Fatalf("Error: expected cursor at 5 but was %d", pos)
+	}
+
+	textinput, _ = textinput.Update(altKey('c'))
+	textinput, _ = textinput.Update(altKey('c'))
+	if v := textinput.Value(); v != "ÄRGER  Über École" {
+		t.Fatalf("Error: expected %q but was %q", "ÄRGER  Über École", v)
+	}
+	if pos := textinput.Position(); pos != 17 {
+		t.Fatalf("Error: expected cursor at 17 but was %d", pos)
+	}
+
+	textinput.CursorStart()
+	textinput, _ = textinput.Update(altKey('l'))
+	if v := textinput.Value(); v != "ärger  Über École" {
+		t.Fatalf("Error: expected %q but was %q", "ärger  Über École", v)
+	}
+}
+
+func Test_PromptAndTextStyle(t *testing.T) {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI)
+
+	textinput := New()
+	textinput.Prompt = "$ "
+	textinput.PromptStyle = renderer.NewStyle().Bold(true)
+	textinput.TextStyle = renderer.NewStyle().Underline(true)
+	textinput.SetValue("ls")
+
+	view := textinput.View()
+	if !strings.Contains(view, "\x1b[1m$ \x1b[0m") {
+		t.Fatalf("Error: expected bold prompt in %q", view)
+	}
+	if !strings.HasSuffix(view, "\x1b[4;4ml\x1b[0m\x1b[4;4ms\x1b[0m ") {
+		t.Fatalf("Error: expected underlined text in %q", view)
+	}
+}
+
+func Test_SetCursorClamps(t *testing.T) {
+	textinput := New()
+	textinput.SetValue("naïve")
+
+	for _, tc := range []struct{ pos, want int }{
+		{-3, 0},
+		{3, 3},
+		{42, 5},
+	} {
+		textinput.SetCursor(tc.pos)
+		if pos := textinput.Position(); pos != tc.want {
+			t.Fatalf("Error: expected SetCursor(%d) to place the cursor at %d but was %d", tc.pos, tc.want, pos)
+		}
+	}
+}
+
+func Test_RuneAndWordCount(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		runes int
+		words int
+	}{
+		{"", 0, 0},
+		{"   ", 3, 0},
+		{"  hello   world ", 16, 2},
+		{"naïve café 日本", 13, 3},
+	} {
+		textinput := New()
+		textinput.SetValue(tc.value)
+		if n := textinput.RuneCount(); n != tc.runes {
+			t.Fatalf("Error: expected %q to have %d runes but got %d", tc.value, tc.runes, n)
+		}
+		if n := textinput.WordCount(); n != tc.words {
+			t.Fatalf("Error: expected %q to have %d words but got %d", tc.value, tc.words, n)
+		}
+	}
+}
+
+func Test_CursorGlyph(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Cursor.Glyph = "▏"
+	textinput.Focus()
+	textinput.SetValue("ab")
+	textinput.SetCursor(1)
+
+	if view := ansi.Strip(textinput.View()); view != "a▏" {
+		t.Fatalf("Error: expected the glyph in place of the cursor but got %q", view)
+	}
+
+	textinput.Cursor.Blink = true
+	if view := ansi.Strip(textinput.View()); view != "ab" {
+		t.Fatalf("Error: expected the character under the hidden cursor but got %q", view)
+	}
+}
+
+func Test_OnChange(t *testing.T) {
+	var changes []string
+
+	textinput := New()
+	textinput.OnChange = func(s string) {
+		changes = append(changes, s)
+	}
+	textinput.Focus()
+
+	textinput = sendString(textinput, "ab")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	textinput, _ = textinput.Update(pasteMsg("cd"))
+
+	expected := []string{"a", "ab", "a", "cda"}
+	if strings.Join(changes, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Error: expected changes %q but got %q", expected, changes)
+	}
+
+	textinput.SetValue("reset")
+	if len(changes) != len(expected) {
+		t.Fatalf("Error: expected SetValue not to report a change but got %q", changes)
+	}
+}
+
+func Test_Mask(t *testing.T) {
+	textinput := New()
+	textinput.Mask = "(###) ###-####"
+	textinput.Focus()
+
+	textinput = sendString(textinput, "555a123")
+	if v := textinput.Value(); v != "(555) 123" {
+		t.Fatalf("Error: expected %q but was %q", "(555) 123", v)
+	}
+	if textinput.Position() != 9 {
+		t.Fatalf("Error: expected cursor at 9 but was %d", textinput.Position())
+	}
+
+	textinput = sendString(textinput, "456789")
+	if v := textinput.Value(); v != "(555) 123-4567" {
+		t.Fatalf("Error: expected extra digits to be dropped, got %q", v)
+	}
+	if v := textinput.RawValue(); v != "5551234567" {
+		t.Fatalf("Error: expected raw value %q but was %q", "5551234567", v)
+	}
+
+	// Backspace skips over literals to delete the digit before them.
+	textinput.SetCursor(6)
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if v := textinput.Value(); v != "(551) 234-567" {
+		t.Fatalf("Error: expected %q but was %q", "(551) 234-567", v)
+	}
+	if textinput.Position() != 3 {
+		t.Fatalf("Error: expected cursor at 3 but was %d", textinput.Position())
+	}
+
+	textinput.SetValue("12")
+	if v := textinput.Value(); v != "(12" {
+		t.Fatalf("Error: expected SetValue to apply the mask, got %q", v)
+	}
+}
+
+func Test_SuggestionGhostText(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.ShowSuggestions = true
+	textinput.SetSuggestions([]string{"deploy", "delete"})
+	textinput.Focus()
+
+	textinput = sendString(textinput, "de")
+	if view := ansi.Strip(textinput.View()); view != "deploy" {
+		t.Fatalf("Error: expected the completion as ghost text but got %q", view)
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if view := ansi.Strip(textinput.View()); view != "delete" {
+		t.Fatalf("Error: expected the next candidate as ghost text but got %q", view)
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if v := textinput.Value(); v != "delete" {
+		t.Fatalf("Error: expected right at the end to accept %q but was %q", "delete", v)
+	}
+
+	textinput.Reset()
+	textinput = sendString(textinput, "dx")
+	if view := strings.TrimRight(ansi.Strip(textinput.View()), " "); view != "dx" {
+		t.Fatalf("Error: expected no ghost text without a match but got %q", view)
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if v := textinput.Value(); v != "dx" {
+		t.Fatalf("Error: expected tab to do nothing without a match but was %q", v)
+	}
+}
+
+func Test_SuggestionMenu(t *testing.T) {
+	textinput := New()
+	textinput.ShowSuggestions = true
+	textinput.ShowSuggestionMenu = true
+	textinput.SetSuggestions([]string{"commit", "checkout", "cherry-pick", "clone"})
+	textinput.Focus()
+
+	textinput = sendString(textinput, "ch")
+	if !textinput.SuggestionMenuOpen() {
+		t.Fatal("Error: expected the menu to open on an ambiguous prefix")
+	}
+	matched := strings.Join(textinput.MatchedSuggestions(), ",")
+	if matched != "checkout,cherry-pick" {
+		t.Fatalf("Error: expected the matching suggestions but got %q", matched)
+	}
+	if view := ansi.Strip(textinput.SuggestionsView()); view != "  checkout\n  cherry-pick" {
+		t.Fatalf("Error: expected the menu below the text but got %q", view)
+	}
+
+	// Tab cycles the menu rather than accepting.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if textinput.CurrentSuggestionIndex() != 1 || textinput.Value() != "ch" {
+		t.Fatalf("Error: expected tab to select the next suggestion, got %d and %q",
+			textinput.CurrentSuggestionIndex(), textinput.Value())
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if textinput.CurrentSuggestionIndex() != 1 {
+		t.Fatalf("Error: expected shift+tab to wrap around, got %d", textinput.CurrentSuggestionIndex())
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if v := textinput.Value(); v != "cherry-pick" {
+		t.Fatalf("Error: expected enter to accept %q but was %q", "cherry-pick", v)
+	}
+	if textinput.SuggestionMenuOpen() || textinput.SuggestionsView() != "" {
+		t.Fatal("Error: expected the menu to close on accept")
+	}
+
+	// A single match doesn't open the menu.
+	textinput.Reset()
+	textinput = sendString(textinput, "cl")
+	if textinput.SuggestionMenuOpen() {
+		t.Fatal("Error: expected no menu for a single match")
+	}
+}
+
+func Test_KillAndYank(t *testing.T) {
+	yank := tea.KeyMsg{Type: tea.KeyCtrlY}
+
+	textinput := New()
+	textinput.Focus()
+	textinput = sendString(textinput, "hello big world")
+
+	// Consecutive backward kills accumulate into one entry.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if v := textinput.Value(); v != "hello " {
+		t.Fatalf("Error: expected %q but was %q", "hello ", v)
+	}
+
+	textinput.CursorStart()
+	textinput, _ = textinput.Update(yank)
+	if v := textinput.Value(); v != "big worldhello " {
+		t.Fatalf("Error: expected the killed text to be yanked at the cursor, got %q", v)
+	}
+	if pos := textinput.Position(); pos != 9 {
+		t.Fatalf("Error: expected cursor after the yanked text at 9 but was %d", pos)
+	}
+
+	// A kill after another key starts a new entry.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	textinput.CursorStart()
+	textinput, _ = textinput.Update(yank)
+	if v := textinput.Value(); v != "hello big world" {
+		t.Fatalf("Error: expected only the latest kill to be yanked, got %q", v)
+	}
+
+	// Text killed from a password isn't kept.
+	textinput.Reset()
+	textinput.EchoMode = EchoPassword
+	textinput = sendString(textinput, "secret")
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	textinput, _ = textinput.Update(yank)
+	if v := textinput.Value(); v != "hello " {
+		t.Fatalf("Error: expected the earlier kill to be yanked, got %q", v)
+	}
+}
+
+func Test_WordSeparators(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput.WordSeparators = "/_-."
+	textinput = sendString(textinput, "/usr/local/my_file.txt")
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if v := textinput.Value(); v != "/usr/local/my_file." {
+		t.Fatalf("Error: expected %q but was %q", "/usr/local/my_file.", v)
+	}
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft, Alt: true})
+	if pos := textinput.Position(); pos != 14 {
+		t.Fatalf("Error: expected cursor at the start of %q (14) but was %d", "file", pos)
+	}
+
+	textinput.CursorStart()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRight, Alt: true})
+	if pos := textinput.Position(); pos != 4 {
+		t.Fatalf("Error: expected cursor after %q (4) but was %d", "usr", pos)
+	}
+
+	// By default only whitespace separates words.
+	textinput.WordSeparators = ""
+	textinput.CursorEnd()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+	if v := textinput.Value(); v != "" {
+		t.Fatalf("Error: expected the whole path to be deleted but was %q", v)
+	}
+}
+
+func Test_ValueTrimmed(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput = sendString(textinput, "  hello world ")
+
+	if v := textinput.ValueTrimmed(); v != "hello world" {
+		t.Fatalf("Error: expected %q but was %q", "hello world", v)
+	}
+	if v := textinput.Value(); v != "  hello world " {
+		t.Fatalf("Error: expected the value to be left as is but was %q", v)
+	}
+
+	textinput.SetValue("   ")
+	if v := textinput.ValueTrimmed(); v != "" {
+		t.Fatalf("Error: expected spaces only to trim to empty but was %q", v)
+	}
+}
+
+func Test_SetBlinkOff(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Cursor.Glyph = "_"
+	textinput.Focus()
+	if textinput.BlinkOff() {
+		t.Fatal("Error: expected a new input's cursor to be in the on phase")
+	}
+
+	textinput.SetBlinkOff(true)
+	if !textinput.BlinkOff() {
+		t.Fatal("Error: expected the cursor to be in the off phase")
+	}
+	if view := ansi.Strip(textinput.View()); view != " " {
+		t.Fatalf("Error: expected the cursor to be hidden but got %q", view)
+	}
+
+	textinput.SetBlinkOff(false)
+	if textinput.BlinkOff() {
+		t.Fatal("Error: expected the cursor to be in the on phase")
+	}
+	if view := ansi.Strip(textinput.View()); view != "_" {
+		t.Fatalf("Error: expected the cursor to be shown but got %q", view)
+	}
+}
+
+func Test_SelectAll(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput = sendString(textinput, "hello wörld")
+	textinput.SetCursor(3)
+
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}, Alt: true})
+	if sel := textinput.SelectedText(); sel != "hello wörld" {
+		t.Fatalf("Error: expected the whole value to be selected but was %q", sel)
+	}
+
+	textinput = sendString(textinput, "x")
+	if v := textinput.Value(); v != "x" {
+		t.Fatalf("Error: expected typing to replace the selection but was %q", v)
+	}
+
+	textinput.SetValue("again")
+	textinput.SelectAll()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if v := textinput.Value(); v != "" {
+		t.Fatalf("Error: expected backspace to delete the selection but was %q", v)
+	}
+}
+
+func Test_InsertString(t *testing.T) {
+	textinput := New()
+	textinput.SetValue("ac")
+	textinput.SetCursor(1)
+
+	textinput.InsertRune('b')
+	textinput.InsertString("😀")
+	if v := textinput.Value(); v != "ab😀c" {
+		t.Fatalf("Error: expected %q but was %q", "ab😀c", v)
+	}
+	if pos := textinput.Position(); pos != 3 {
+		t.Fatalf("Error: expected cursor at 3 but was %d", pos)
+	}
+
+	// The char limit truncates the inserted text.
+	textinput.CharLimit = 6
+	textinput.InsertString("xyz")
+	if v := textinput.Value(); v != "ab😀xyc" {
+		t.Fatalf("Error: expected %q but was %q", "ab😀xyc", v)
+	}
+
+	// Filtered runes are dropped.
+	textinput.CharLimit = 0
+	textinput.CharFilter = unicode.IsLetter
+	textinput.InsertString("1d2e")
+	if v := textinput.Value(); v != "ab😀xydec" {
+		t.Fatalf("Error: expected %q but was %q", "ab😀xydec", v)
+	}
+}
+
+func Test_InsertStringSuggestions(t *testing.T) {
+	textinput := New()
+	textinput.ShowSuggestions = true
+	textinput.SetSuggestions([]string{"abc"})
+	textinput.Focus()
+	textinput = sendString(textinput, "a")
+
+	// Inserting text matches the suggestions again.
+	textinput.InsertString("hello")
+	if s := textinput.CurrentSuggestion(); s != "" {
+		t.Fatalf("Error: expected no suggestion but got %q", s)
+	}
+	_ = textinput.View()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRight})
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if v := textinput.Value(); v != "ahello" {
+		t.Fatalf("Error: expected %q but was %q", "ahello", v)
+	}
+
+	// A suggestion the value no longer matches isn't accepted.
+	textinput.Reset()
+	textinput = sendString(textinput, "a")
+	textinput.value = []rune("ahello")
+	textinput.CursorEnd()
+	_ = textinput.View()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if v := textinput.Value(); v != "ahello" {
+		t.Fatalf("Error: expected %q but was %q", "ahello", v)
+	}
+}
+
+func Test_Truncate(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.SetValue("abcdefghij")
+
+	tests := []struct {
+		mode  TruncateMode
+		width int
+		want  string
+	}{
+		{TruncateEnd, 5, "abcd…"},
+		{TruncateEnd, 9, "abcdefgh…"},
+		{TruncateEnd, 10, "abcdefghij"},
+		{TruncateMiddle, 5, "ab…ij"},
+		{TruncateMiddle, 6, "abc…ij"},
+		{TruncateMiddle, 9, "abcd…ghij"},
+	}
+	for _, tc := range tests {
+		textinput.Truncate = tc.mode
+		textinput.Width = tc.width
+		if view := strings.TrimRight(ansi.Strip(textinput.View()), " "); view != tc.want {
+			t.Fatalf("Error: expected %q at width %d but was %q", tc.want, tc.width, view)
+		}
+	}
+
+	// Wide characters are never split.
+	textinput.SetValue("日本語中文")
+	textinput.Truncate = TruncateMiddle
+	textinput.Width = 6
+	if view := ansi.Strip(textinput.View()); view != "日…文" {
+		t.Fatalf("Error: expected %q but was %q", "日…文", view)
+	}
+
+	// Focused inputs scroll instead.
+	textinput.Focus()
+	if view := ansi.Strip(textinput.View()); strings.Contains(view, "…") {
+		t.Fatalf("Error: expected no truncation while focused but was %q", view)
+	}
+}
+
+func Test_CursorColumn(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = "❯ "
+	textinput.PromptStyle = lipgloss.NewStyle().Bold(true).PaddingLeft(2)
+	textinput.Focus()
+
+	if col := textinput.CursorColumn(); col != 4 {
+		t.Fatalf("Error: expected the cursor after the prompt at 4 but was %d", col)
+	}
+
+	textinput = sendString(textinput, "日本")
+	if col := textinput.CursorColumn(); col != 8 {
+		t.Fatalf("Error: expected the cursor at 8 but was %d", col)
+	}
+
+	// With a scrolled window only the visible text counts.
+	textinput.Width = 5
+	textinput = sendString(textinput, "abcdefgh")
+	if col := textinput.CursorColumn(); col != 9 {
+		t.Fatalf("Error: expected the cursor at 9 but was %d", col)
+	}
+	textinput.CursorStart()
+	if col := textinput.CursorColumn(); col != 4 {
+		t.Fatalf("Error: expected the cursor at 4 but was %d", col)
+	}
+}
+
+func Test_Reveal(t *testing.T) {
+	ctrlR := tea.KeyMsg{Type: tea.KeyCtrlR}
+
+	textinput := New()
+	textinput.EchoMode = EchoPassword
+	textinput.EchoCharacter = '*'
+	textinput.Focus()
+	textinput = sendString(textinput, "secret")
+
+	masked := func() bool {
+		view := textinput.View()
+		if strings.Contains(view, "secre") {
+			return false
+		}
+		if !strings.Contains(view, "*****") {
+			t.Fatalf("Error: expected the view to be masked or plain but got %q", view)
+		}
+		return true
+	}
+
+	if !masked() {
+		t.Fatal("Error: expected the password to be masked")
+	}
+	textinput, _ = textinput.Update(ctrlR)
+	if masked() || !textinput.Revealed() {
+		t.Fatal("Error: expected the password to be revealed")
+	}
+	textinput, _ = textinput.Update(ctrlR)
+	if !masked() {
+		t.Fatal("Error: expected the password to be concealed again")
+	}
+
+	textinput.Reveal()
+	if masked() {
+		t.Fatal("Error: expected Reveal to show the password")
+	}
+	textinput.Conceal()
+	if !masked() {
+		t.Fatal("Error: expected Conceal to hide the password")
+	}
+
+	// Blurring conceals the value.
+	textinput.Reveal()
+	textinput.Blur()
+	if textinput.Revealed() || !masked() {
+		t.Fatal("Error: expected blurring to conceal the password")
+	}
+}
+
+func Test_PasteNewlineBehavior(t *testing.T) {
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("one\ntwo"), Paste: true}
+
+	textinput := New()
+	textinput.Cursor.SetMode(cursor.CursorStatic)
+	textinput.Focus()
+
+	// Newlines are replaced with spaces by default.
+	textinput, cmd := textinput.Update(paste)
+	if v := textinput.Value(); v != "one two" {
+		t.Fatalf("Error: expected %q but was %q", "one two", v)
+	}
+	if _, ok := submitted(cmd); ok {
+		t.Fatal("Error: expected the paste not to submit the input")
+	}
+
+	// Or the text up to the first newline is pasted and submitted, and the
+	// rest is discarded.
+	textinput.Reset()
+	textinput.PasteNewlineBehavior = PasteNewlineSubmit
+	textinput = sendString(textinput, "> ")
+	textinput, cmd = textinput.Update(paste)
+	if v := textinput.Value(); v != "> one" {
+		t.Fatalf("Error: expected %q but was %q", "> one", v)
+	}
+	if v, ok := submitted(cmd); !ok || v != "> one" {
+		t.Fatalf("Error: expected the paste to submit %q but got %q (%v)", "> one", v, ok)
+	}
+
+	// The same goes for pasting from the clipboard.
+	textinput.Reset()
+	textinput, cmd = textinput.Update(pasteMsg("three\r\nfour"))
+	if v, ok := submitted(cmd); !ok || v != "three" {
+		t.Fatalf("Error: expected the paste to submit %q but got %q (%v)", "three", v, ok)
+	}
+
+	// Pastes without newlines don't submit.
+	textinput, cmd = textinput.Update(pasteMsg("five"))
+	if _, ok := submitted(cmd); ok {
+		t.Fatal("Error: expected the paste not to submit the input")
+	}
+}
+
+func Test_SubmitOnEnter(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	textinput := New()
+	textinput.Cursor.SetMode(cursor.CursorStatic)
+	textinput.Focus()
+	textinput = sendString(textinput, "hello")
+
+	_, cmd := textinput.Update(enter)
+	if _, ok := submitted(cmd); ok {
+		t.Fatal("Error: expected enter not to submit without SubmitOnEnter")
+	}
+
+	textinput.SubmitOnEnter = true
+	textinput, cmd = textinput.Update(enter)
+	if v, ok := submitted(cmd); !ok || v != "hello" {
+		t.Fatalf("Error: expected enter to submit %q but got %q (%v)", "hello", v, ok)
+	}
+	if v := textinput.Value(); v != "hello" {
+		t.Fatalf("Error: expected submitting to keep the value but was %q", v)
+	}
+
+	// Enter accepts a suggestion from the open menu instead.
+	textinput.Reset()
+	textinput.ShowSuggestions = true
+	textinput.ShowSuggestionMenu = true
+	textinput.SetSuggestions([]string{"apple", "apricot"})
+	textinput = sendString(textinput, "ap")
+	textinput, cmd = textinput.Update(enter)
+	if _, ok := submitted(cmd); ok {
+		t.Fatal("Error: expected enter to accept the suggestion rather than submit")
+	}
+	if v := textinput.Value(); v != "apple" {
+		t.Fatalf("Error: expected the suggestion to be accepted but value was %q", v)
+	}
+	_, cmd = textinput.Update(enter)
+	if v, ok := submitted(cmd); !ok || v != "apple" {
+		t.Fatalf("Error: expected enter to submit %q but got %q (%v)", "apple", v, ok)
+	}
+}
+
+func Test_DisabledBindings(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput.KeyMap.LineStart.SetEnabled(false)
+	textinput.KeyMap.LineEnd.SetEnabled(false)
+	textinput.KeyMap.DeleteAfterCursor.SetEnabled(false)
+	textinput = sendString(textinput, "hello")
+	textinput.SetCursor(2)
+
+	for _, msg := range []tea.KeyMsg{
+		{Type: tea.KeyCtrlA},
+		{Type: tea.KeyCtrlE},
+		{Type: tea.KeyCtrlK},
+	} {
+		textinput, _ = textinput.Update(msg)
+		if v := textinput.Value(); v != "hello" {
+			t.Fatalf("Error: expected disabled %s to leave the value alone but was %q", msg, v)
+		}
+		if pos := textinput.Position(); pos != 2 {
+			t.Fatalf("Error: expected disabled %s to leave the cursor at 2 but was %d", msg, pos)
+		}
+	}
+
+	// Disabling a binding disables all of its keys. To give up just one of
+	// them, rebind the action to the others.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if pos := textinput.Position(); pos != 2 {
+		t.Fatalf("Error: expected a disabled binding to disable all of its keys, but cursor was at %d", pos)
+	}
+	textinput.KeyMap.LineEnd = key.NewBinding(key.WithKeys("end"))
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if pos := textinput.Position(); pos != 2 {
+		t.Fatalf("Error: expected ctrl+e to be left alone but cursor was at %d", pos)
+	}
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyEnd})
+	if pos := textinput.Position(); pos != 5 {
+		t.Fatalf("Error: expected end to move the cursor to 5 but was %d", pos)
+	}
+}
+
+func Test_GraphemeClusters(t *testing.T) {
+	const (
+		family = "👨‍👩‍👧" // joined with zero width joiners
+		flag   = "🇩🇪"    // a pair of regional indicators
+	)
+	left := tea.KeyMsg{Type: tea.KeyLeft}
+	right := tea.KeyMsg{Type: tea.KeyRight}
+
+	textinput := New()
+	textinput.Focus()
+	textinput.SetValue("a" + family + flag + "b")
+
+	// Each glyph is one cursor step, however many runes it's made of.
+	positions := []int{0, 1, 6, 8, 9}
+	textinput.CursorStart()
+	for i, want := range positions {
+		if i > 0 {
+			textinput, _ = textinput.Update(right)
+		}
+		if pos := textinput.Position(); pos != want {
+			t.Fatalf("Error: expected cursor at %d but was %d", want, pos)
+		}
+	}
+	for i := len(positions) - 2; i >= 0; i-- {
+		textinput, _ = textinput.Update(left)
+		if pos := textinput.Position(); pos != positions[i] {
+			t.Fatalf("Error: expected cursor at %d but was %d", positions[i], pos)
+		}
+	}
+
+	// The cursor covers the whole glyph.
+	textinput.SetCursor(1)
+	if view := ansi.Strip(textinput.View()); view != "> a"+family+flag+"b" {
+		t.Fatalf("Error: expected the glyph to be rendered intact but was %q", view)
+	}
+
+	// Backspace and delete remove whole glyphs.
+	textinput.SetCursor(8)
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if v := textinput.Value(); v != "a"+family+"b" {
+		t.Fatalf("Error: expected the flag to be deleted but was %q", v)
+	}
+	textinput.SetCursor(1)
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if v := textinput.Value(); v != "ab" {
+		t.Fatalf("Error: expected the family to be deleted but was %q", v)
+	}
+}
+
+func Test_LimitIndicator(t *testing.T) {
+	textinput := New()
+	textinput.CharLimit = 3
+	textinput.LimitIndicator = "!"
+	textinput.LimitIndicatorStyle = lipgloss.NewStyle()
+	textinput.Focus()
+
+	shown := func() bool {
+		return strings.HasSuffix(ansi.Strip(textinput.View()), "!")
+	}
+
+	// Reaching the limit doesn't show the indicator, going past it does.
+	textinput = sendString(textinput, "abc")
+	if shown() {
+		t.Fatal("Error: expected no indicator at the limit")
+	}
+	textinput = sendString(textinput, "d")
+	if v := textinput.Value(); v != "abc" {
+		t.Fatalf("Error: expected %q but was %q", "abc", v)
+	}
+	if !shown() {
+		t.Fatal("Error: expected the indicator after a rejected key")
+	}
+
+	// The next key press hides it.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if shown() {
+		t.Fatal("Error: expected the indicator to be hidden after the next key")
+	}
+
+	// Pastes that are cut short show it too.
+	textinput, _ = textinput.Update(pasteMsg("xyz"))
+	if v := textinput.Value(); v != "abx" {
+		t.Fatalf("Error: expected %q but was %q", "abx", v)
+	}
+	if !shown() {
+		t.Fatal("Error: expected the indicator after a truncated paste")
+	}
+
+	// Replacing the value hides it.
+	textinput.Reset()
+	if shown() {
+		t.Fatal("Error: expected the indicator to be hidden after a reset")
+	}
+	textinput = sendString(textinput, "abcd")
+	textinput.SetValue("x")
+	if shown() {
+		t.Fatal("Error: expected the indicator to be hidden after setting the value")
+	}
+}
+
+func Test_Suffix(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.Suffix = " kg"
+	textinput.Width = 8
+	textinput.Focus()
+	textinput = sendString(textinput, "1234567890")
+
+	// The suffix takes 3 of the 8 columns, leaving 5 for the value and the
+	// cursor after it.
+	view := ansi.Strip(textinput.View())
+	if expected := "67890  kg"; view != expected {
+		t.Fatalf("Error: expected view %q but was %q", expected, view)
+	}
+
+	// Short values are padded up to the suffix.
+	textinput.SetValue("12")
+	view = ansi.Strip(textinput.View())
+	if expected := "12     kg"; view != expected {
+		t.Fatalf("Error: expected view %q but was %q", expected, view)
+	}
+
+	// Without a width, the suffix follows the cursor directly.
+	textinput.Width = 0
+	view = ansi.Strip(textinput.View())
+	if expected := "12  kg"; view != expected {
+		t.Fatalf("Error: expected view %q but was %q", expected, view)
+	}
+}
+
+func Test_Redraw(t *testing.T) {
+	textinput := New()
+	textinput.Focus()
+	textinput = sendString(textinput, "hello")
+	textinput.SetCursor(2)
+
+	textinput, cmd := textinput.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if v := textinput.Value(); v != "hello" {
+		t.Fatalf("Error: expected %q but was %q", "hello", v)
+	}
+	if pos := textinput.Position(); pos != 2 {
+		t.Fatalf("Error: expected the cursor at 2 but was at %d", pos)
+	}
+	if cmd == nil {
+		t.Fatal("Error: expected a command")
+	}
+	if _, ok := cmd().(RedrawMsg); !ok {
+		t.Fatalf("Error: expected a RedrawMsg but got %T", cmd())
+	}
+}
+
+func Test_EchoRevealLast(t *testing.T) {
+	textinput := New()
+	textinput.Prompt = ""
+	textinput.EchoMode = EchoPassword
+	textinput.EchoRevealLast = true
+	textinput.EchoRevealDuration = time.Millisecond
+	textinput.Cursor.SetMode(cursor.CursorStatic)
+	textinput.Focus()
+
+	view := func() string {
+		return strings.TrimSpace(ansi.Strip(textinput.View()))
+	}
+
+	// Each typed character is shown until the next one.
+	textinput = sendString(textinput, "ab")
+	var cmd tea.Cmd
+	textinput, cmd = textinput.Update(keyPress('c'))
+	if v := view(); v != "**c" {
+		t.Fatalf("Error: expected %q right after typing but was %q", "**c", v)
+	}
+
+	// It's masked once the timer fires.
+	if cmd == nil {
+		t.Fatal("Error: expected a command to mask the last character")
+	}
+	textinput, _ = textinput.Update(cmd())
+	if v := view(); v != "***" {
+		t.Fatalf("Error: expected %q after the timer but was %q", "***", v)
+	}
+
+	// A timer from an earlier character doesn't mask a later one.
+	textinput, stale := textinput.Update(keyPress('d'))
+	textinput, _ = textinput.Update(keyPress('e'))
+	textinput, _ = textinput.Update(stale())
+	if v := view(); v != "****e" {
+		t.Fatalf("Error: expected %q after a stale timer but was %q", "****e", v)
+	}
+
+	// Other keys mask it right away, and pastes aren't shown.
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if v := view(); v != "*****" {
+		t.Fatalf("Error: expected %q after moving the cursor but was %q", "*****", v)
+	}
+	textinput.CursorEnd()
+	textinput, _ = textinput.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("fg"), Paste: true})
+	if v := view(); v != "*******" {
+		t.Fatalf("Error: expected %q after a paste but was %q", "*******", v)
+	}
+}
+
+func keyPress(key rune) tea.Msg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}, Alt: false}
+}
+
+func sendString(m Model, str string) Model {
+	for _, k := range []rune(str) {
+		m, _ = m.Update(keyPress(k))
+	}
+
+	return m
+}
+
+// submitted runs cmd and returns the value of the SubmitMsg it sends, if any.
+func submitted(cmd tea.Cmd) (string, bool) {
+	if cmd == nil {
+		return "", false
+	}
+	switch msg := cmd().(type) {
+	case SubmitMsg:
+		return msg.Value, true
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if v, ok := submitted(cmd); ok {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
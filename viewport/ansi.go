@@ -0,0 +1,307 @@
+package viewport
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi/parser"
+	"github.com/mattn/go-runewidth"
+)
+
+// sgrReset is the sequence that resets all styling.
+const sgrReset = "\x1b[0m"
+
+// segment is a run of either printable text or ANSI escape sequences.
+type segment struct {
+	s   string
+	esc bool
+}
+
+// segments splits s into runs of printable text and escape sequences. The
+// printable runs joined together are equal to ansi.Strip(s).
+func segments(s string) []segment {
+	var (
+		segs   []segment
+		start  int
+		esc    bool
+		ri, rw int
+		pstate = parser.GroundState
+	)
+
+	for i := 0; i < len(s); i++ {
+		var state, action byte
+		if pstate != parser.Utf8State {
+			state, action = parser.Table.Transition(pstate, s[i])
+		}
+
+		isText := pstate == parser.Utf8State ||
+			action == parser.PrintAction ||
+			action == parser.ExecuteAction
+		if i == 0 {
+			esc = !isText
+		} else if esc == isText {
+			segs = append(segs, segment{s: s[start:i], esc: esc})
+			start = i
+			esc = !isText
+		}
+
+		switch {
+		case pstate == parser.Utf8State:
+			ri++
+			if ri < rw {
+				continue
+			}
+			pstate = parser.GroundState
+			ri, rw = 0, 0
+			continue
+		case action == parser.PrintAction:
+			if w := utf8ByteLen(s[i]); w > 1 {
+				rw = w
+				ri = 1
+				pstate = parser.Utf8State
+				continue
+			}
+		}
+		pstate = state
+	}
+	if start < len(s) {
+		segs = append(segs, segment{s: s[start:], esc: esc})
+	}
+	return segs
+}
+
+// utf8ByteLen returns the length of the UTF-8 sequence starting with b.
+func utf8ByteLen(b byte) int {
+	switch {
+	case b <= 0x7F:
+		return 1
+	case b >= 0xC0 && b <= 0xDF:
+		return 2
+	case b >= 0xE0 && b <= 0xEF:
+		return 3
+	case b >= 0xF0 && b <= 0xF7:
+		return 4
+	}
+	return -1
+}
+
+// expandTabs replaces the tabs in line with spaces up to the next multiple of
+// tabWidth columns. Escape sequences don't count towards the column.
+func expandTabs(line string, tabWidth int) string {
+	if tabWidth <= 0 || !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var (
+		b   strings.Builder
+		col int
+	)
+	for _, seg := range segments(line) {
+		if seg.esc {
+			b.WriteString(seg.s)
+			continue
+		}
+		for _, r := range seg.s {
+			if r == '\t' {
+				n := tabWidth - col%tabWidth
+				b.WriteString(strings.Repeat(" ", n))
+				col += n
+				continue
+			}
+			b.WriteRune(r)
+			col += runewidth.RuneWidth(r)
+		}
+	}
+	return b.String()
+}
+
+// cutLeft removes the first n columns of line's printable text. Escape
+// sequences are kept so that styling carries over, and a wide character that's
+// cut in half is replaced with spaces.
+func cutLeft(line string, n int) string {
+	if n <= 0 {
+		return line
+	}
+
+	var (
+		b   strings.Builder
+		col int
+	)
+	for _, seg := range segments(line) {
+		if seg.esc || col >= n {
+			b.WriteString(seg.s)
+			continue
+		}
+		for i, r := range seg.s {
+			if col >= n {
+				b.WriteString(seg.s[i:])
+				break
+			}
+			col += runewidth.RuneWidth(r)
+			if col > n {
+				b.WriteString(strings.Repeat(" ", col-n))
+			}
+		}
+	}
+	return b.String()
+}
+
+// wordStarts returns the columns at which the whitespace-delimited words of
+// the printable text s start.
+func wordStarts(s string) (starts []int) {
+	col, space := 0, true
+	for _, r := range s {
+		if space && !unicode.IsSpace(r) {
+			starts = append(starts, col)
+		}
+		space = unicode.IsSpace(r)
+		col += runewidth.RuneWidth(r)
+	}
+	return starts
+}
+
+// wrapRows splits line into rows of at most first columns for the first row
+// and rest columns for the others. Wide characters are never split, and
+// escape sequences are kept where they are. Styling that's in effect where a
+// row ends is reset at its end and reopened at the start of the next row.
+func wrapRows(line string, first, rest int) []string {
+	var (
+		rows   []string
+		b      strings.Builder
+		active strings.Builder // SGR sequences in effect
+		col    int
+		limit  = first
+	)
+	for _, seg := range segments(line) {
+		if seg.esc {
+			b.WriteString(seg.s)
+			trackSGR(&active, seg.s)
+			continue
+		}
+		for _, r := range seg.s {
+			w := runewidth.RuneWidth(r)
+			if col > 0 && col+w > limit {
+				if active.Len() > 0 {
+					b.WriteString(sgrReset)
+				}
+				rows = append(rows, b.String())
+				b.Reset()
+				b.WriteString(active.String())
+				col, limit = 0, rest
+			}
+			b.WriteRune(r)
+			col += w
+		}
+	}
+	return append(rows, b.String())
+}
+
+// findRanges returns the rune ranges of the non-overlapping occurrences of
+// query in text.
+func findRanges(text, query []rune, caseSensitive bool) (ranges [][2]int) {
+	if len(query) == 0 {
+		return nil
+	}
+	equal := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	for i := 0; i+len(query) <= len(text); i++ {
+		match := true
+		for j, r := range query {
+			if !equal(text[i+j], r) {
+				match = false
+				break
+			}
+		}
+		if match {
+			ranges = append(ranges, [2]int{i, i + len(query)})
+			i += len(query) - 1
+		}
+	}
+	return ranges
+}
+
+// highlight renders the given rune ranges of the line's printable text with
+// style. Escape sequences in the line are preserved, and any SGR styling that
+// was active before a highlighted range is restored after it.
+func highlight(line string, ranges [][2]int, style lipgloss.Style) string {
+	if len(ranges) == 0 {
+		return line
+	}
+
+	var (
+		b      strings.Builder
+		active strings.Builder // SGR sequences in effect
+		match  strings.Builder // highlighted text not yet written
+		idx    int             // rune index into the printable text
+		ri     int             // index of the next range
+	)
+
+	flush := func() {
+		if match.Len() == 0 {
+			return
+		}
+		b.WriteString(style.Render(match.String()))
+		b.WriteString(active.String())
+		match.Reset()
+	}
+
+	for _, seg := range segments(line) {
+		if seg.esc {
+			flush()
+			b.WriteString(seg.s)
+			trackSGR(&active, seg.s)
+			continue
+		}
+		for _, r := range seg.s {
+			for ri < len(ranges) && idx >= ranges[ri][1] {
+				flush()
+				ri++
+			}
+			if ri < len(ranges) && idx >= ranges[ri][0] {
+				match.WriteRune(r)
+			} else {
+				b.WriteRune(r)
+			}
+			idx++
+		}
+	}
+	flush()
+
+	return b.String()
+}
+
+// closeSGR appends a reset to line if it leaves any styling in effect, so that
+// the styling doesn't leak past the end of the line.
+func closeSGR(line string) string {
+	var active strings.Builder
+	for _, seg := range segments(line) {
+		if seg.esc {
+			trackSGR(&active, seg.s)
+		}
+	}
+	if active.Len() > 0 {
+		return line + sgrReset
+	}
+	return line
+}
+
+// trackSGR records the SGR (styling) sequences in seq that remain in effect,
+// forgetting everything before a reset.
+func trackSGR(active *strings.Builder, seq string) {
+	for _, s := range strings.Split(seq, "\x1b")[1:] {
+		if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "m") {
+			continue
+		}
+		if s == "[m" || s == "[0m" {
+			active.Reset()
+			continue
+		}
+		active.WriteString("\x1b" + s)
+	}
+}
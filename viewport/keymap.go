@@ -15,6 +15,12 @@ type KeyMap struct {
 	HalfPageDown key.Binding
 	Down         key.Binding
 	Up           key.Binding
+	Left         key.Binding
+	Right        key.Binding
+	PageLeft     key.Binding
+	PageRight    key.Binding
+	GotoTop      key.Binding
+	GotoBottom   key.Binding
 }
 
 // DefaultKeyMap returns a set of pager-like default keybindings.
@@ -44,5 +50,29 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("down", "j"),
 			key.WithHelp("↓/j", "down"),
 		),
+		Left: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "scroll left"),
+		),
+		Right: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "scroll right"),
+		),
+		PageLeft: key.NewBinding(
+			key.WithKeys("shift+left"),
+			key.WithHelp("shift+←", "page left"),
+		),
+		PageRight: key.NewBinding(
+			key.WithKeys("shift+right"),
+			key.WithHelp("shift+→", "page right"),
+		),
+		GotoTop: key.NewBinding(
+			key.WithKeys("home", "g"),
+			key.WithHelp("g/home", "go to top"),
+		),
+		GotoBottom: key.NewBinding(
+			key.WithKeys("end", "G"),
+			key.WithHelp("G/end", "go to bottom"),
+		),
 	}
 }
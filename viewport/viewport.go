@@ -1,12 +1,46 @@
 package viewport
 
 import (
+	"fmt"
+	"io"
 	"math"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	scrollbarTrack = "│"
+	scrollbarThumb = "┃"
+
+	// maxCount bounds the numeric prefix typed before a motion.
+	maxCount = 999999999
+)
+
+// MatchPosition is where NextMatch and PrevMatch place the matched line in the
+// viewport.
+type MatchPosition int
+
+// Available match positions.
+const (
+	MatchTop MatchPosition = iota
+	MatchCenter
+	MatchBottom
+)
+
+// Edge is the top or bottom edge of the content, as reported by AtEdgeMsg.
+type Edge int
+
+// Available edges.
+const (
+	EdgeTop Edge = iota
+	EdgeBottom
 )
 
 // New returns a new model with the given width and height as well as default
@@ -14,6 +48,7 @@ import (
 func New(width, height int) (m Model) {
 	m.Width = width
 	m.Height = height
+	m.TabWidth = 4
 	m.setInitialValues()
 	return m
 }
@@ -24,6 +59,10 @@ type Model struct {
 	Height int
 	KeyMap KeyMap
 
+	// DisableDefaultKeys makes Update ignore key messages altogether, leaving
+	// scrolling to explicit method calls.
+	DisableDefaultKeys bool
+
 	// Whether or not to respond to the mouse. The mouse must be enabled in
 	// Bubble Tea for this to work. For details, see the Bubble Tea docs.
 	MouseWheelEnabled bool
@@ -31,15 +70,42 @@ type Model struct {
 	// The number of lines the mouse wheel will scroll. By default, this is 3.
 	MouseWheelDelta int
 
+	// ReportEdges makes Update send an AtEdgeMsg when a key or the mouse wheel
+	// tries to scroll past the top or bottom of the content, which would
+	// otherwise be ignored.
+	ReportEdges bool
+
+	// The number of lines HalfViewDown and HalfViewUp scroll by. By default,
+	// this is 0, meaning half the height of the viewport.
+	HalfPageLines int
+
 	// YOffset is the vertical scroll position.
 	YOffset int
 
+	// XOffset is the horizontal scroll position, in columns. Lines wider than
+	// the viewport are cut off at its edges and can be scrolled sideways,
+	// unless SoftWrap is set. Horizontal scrolling isn't supported with high
+	// performance rendering.
+	XOffset int
+
+	// Follow keeps the viewport scrolled to the bottom as content is added,
+	// which is useful for tailing logs. Following pauses while the user is
+	// scrolled up and resumes once they return to the bottom.
+	Follow bool
+
+	// TabWidth is the number of columns between tab stops. Tabs in the
+	// content are expanded to spaces when it's set; 0 leaves them as is. New
+	// sets it to 4.
+	TabWidth int
+
 	// YPosition is the position of the viewport in relation to the terminal
 	// window. It's used in high performance rendering only.
 	YPosition int
 
 	// Style applies a lipgloss style to the viewport. Realistically, it's most
-	// useful for setting borders, margins and padding.
+	// useful for setting borders, margins and padding. The frame is rendered
+	// within Width and Height, so the content area shrinks to make room for
+	// it.
 	Style lipgloss.Style
 
 	// HighPerformanceRendering bypasses the normal Bubble Tea renderer to
@@ -52,17 +118,118 @@ type Model struct {
 	// which is usually via the alternate screen buffer.
 	HighPerformanceRendering bool
 
+	// SearchCaseSensitive makes Search match letter case exactly.
+	SearchCaseSensitive bool
+
+	// MatchScrollPosition is where NextMatch and PrevMatch place the matched
+	// line: at the top of the viewport, which is the default, in its center
+	// or at its bottom. Lines near the ends of the content may not reach the
+	// position, as the viewport never scrolls past them.
+	MatchScrollPosition MatchPosition
+
+	// HighlightStyle is applied to search matches in the view, and
+	// CurrentMatchStyle to the matches on the line selected with NextMatch or
+	// PrevMatch.
+	HighlightStyle    lipgloss.Style
+	CurrentMatchStyle lipgloss.Style
+
+	// ShowLineNumbers prefixes each line with its line number, styled with
+	// LineNumberStyle.
+	ShowLineNumbers bool
+	LineNumberStyle lipgloss.Style
+
+	// SoftWrap wraps lines that are too wide for the viewport onto the rows
+	// below instead of cutting them off, indenting the continuation rows by
+	// WrapIndent columns. Scrolling still moves by whole lines. Soft wrapping
+	// isn't supported with high performance rendering.
+	SoftWrap   bool
+	WrapIndent int
+
+	// ShowScrollbar renders a vertical scrollbar along the right edge of the
+	// viewport, taking up one column of the content width.
+	ShowScrollbar       bool
+	ScrollbarStyle      lipgloss.Style
+	ScrollbarThumbStyle lipgloss.Style
+
+	// ShowPosition renders a footer line with the scroll percentage and the
+	// range of visible lines, taking up one row of the viewport's height.
+	// PositionFormat customizes the footer's contents.
+	ShowPosition   bool
+	PositionFormat func(m Model) string
+
+	// EmptyMessage is shown centered in the viewport, styled with
+	// EmptyMessageStyle, when there are no lines to show.
+	EmptyMessage      string
+	EmptyMessageStyle lipgloss.Style
+
+	// FillChar, when set, is rendered at the start of the rows below the
+	// content, like the tildes in vim. The rows are padded with FillStyle, so
+	// a background color set there covers them entirely.
+	FillChar  rune
+	FillStyle lipgloss.Style
+
+	// HighlightCurrentLine renders the line at index CurrentLine of the shown
+	// lines with CurrentLineStyle, turning the viewport into a simple
+	// selectable list. Up and Down then move the current line instead of
+	// scrolling, and the viewport scrolls to keep it in view.
+	HighlightCurrentLine bool
+	CurrentLine          int
+	CurrentLineStyle     lipgloss.Style
+
 	initialized bool
+
+	// content holds all of the lines and lines the ones shown, which differ
+	// while a filter is set. lineNumbers maps each shown line to its index in
+	// content, and is nil without a filter. raw holds the lines of content as
+	// they were given, before tabs were expanded.
+	raw         []string
+	content     []string
 	lines       []string
+	filter      func(line string) bool
+	lineNumbers []int
+
+	// longestLine is the width of the widest shown line.
+	longestLine int
+
+	// Search state. matches holds the indices of the lines matching
+	// searchQuery and currentMatch the index of the selected match, or -1.
+	searchQuery  string
+	matches      []int
+	currentMatch int
+
+	// count is the numeric prefix typed before a motion, or 0.
+	count int
+
+	// The rows changed by the last content update, if dirty is set.
+	dirty                 bool
+	dirtyFirst, dirtyLast int
 }
 
 func (m *Model) setInitialValues() {
 	m.KeyMap = DefaultKeyMap()
 	m.MouseWheelEnabled = true
 	m.MouseWheelDelta = 3
+
+	// Styles are only defaulted when unset, as they may have been set on a
+	// zero value model before its first Update.
+	defaultStyle(&m.HighlightStyle, lipgloss.NewStyle().Reverse(true))
+	defaultStyle(&m.CurrentMatchStyle, lipgloss.NewStyle().Reverse(true).Bold(true))
+	defaultStyle(&m.LineNumberStyle, lipgloss.NewStyle().Foreground(lipgloss.Color("241")))
+	defaultStyle(&m.ScrollbarStyle, lipgloss.NewStyle().Foreground(lipgloss.Color("238")))
+	defaultStyle(&m.ScrollbarThumbStyle, lipgloss.NewStyle().Foreground(lipgloss.Color("246")))
+	defaultStyle(&m.EmptyMessageStyle, lipgloss.NewStyle().Foreground(lipgloss.Color("241")))
+	defaultStyle(&m.FillStyle, lipgloss.NewStyle().Foreground(lipgloss.Color("241")))
+	defaultStyle(&m.CurrentLineStyle, lipgloss.NewStyle().Reverse(true))
 	m.initialized = true
 }
 
+// defaultStyle sets *s to def if it's the zero value.
+func defaultStyle(s *lipgloss.Style, def lipgloss.Style) {
+	if reflect.DeepEqual(*s, lipgloss.Style{}) {
+		*s = def
+	}
+}
+
 // Init exists to satisfy the tea.Model interface for composability purposes.
 func (m Model) Init() tea.Cmd {
 	return nil
@@ -85,59 +252,673 @@ func (m Model) PastBottom() bool {
 	return m.YOffset > m.maxYOffset()
 }
 
-// ScrollPercent returns the amount scrolled as a float between 0 and 1.
+// ScrollPercent returns the amount scrolled as a float between 0 and 1. When
+// all of the content fits in the viewport it's both at the top and at the
+// bottom, and 1 is returned.
 func (m Model) ScrollPercent() float64 {
-	if m.Height >= len(m.lines) {
+	if m.AtBottom() {
 		return 1.0
 	}
-	y := float64(m.YOffset)
-	h := float64(m.Height)
-	t := float64(len(m.lines))
-	v := y / (t - h)
+	// Not being at the bottom guarantees a positive maximum offset.
+	v := float64(m.YOffset) / float64(m.maxYOffset())
 	return math.Max(0.0, math.Min(1.0, v))
 }
 
-// SetContent set the pager's text content. For high performance rendering the
-// Sync command should also be called.
+// SetScrollPercent scrolls to the offset closest to the given percentage of
+// the content, the inverse of ScrollPercent. p is clamped between 0 and 1.
+// For high performance rendering the Sync command should also be called.
+func (m *Model) SetScrollPercent(p float64) {
+	p = math.Max(0.0, math.Min(1.0, p))
+	m.SetYOffset(int(math.Round(p * float64(m.maxYOffset()))))
+}
+
+// SetContent set the pager's text content. The scroll position is kept, but
+// clamped to the new content. For high performance rendering the Sync command
+// should also be called.
 func (m *Model) SetContent(s string) {
-	s = strings.ReplaceAll(s, "\r\n", "\n") // normalize line endings
-	m.lines = strings.Split(s, "\n")
+	m.setContent(splitLines(s))
+}
+
+// SetContentLines sets the pager's content to the given lines, like
+// SetContent does with the lines joined by newlines, without the round trip.
+// The slice isn't retained. For high performance rendering the Sync command
+// should also be called.
+func (m *Model) SetContentLines(lines []string) {
+	content := make([]string, 0, max(1, len(lines)))
+	for i, line := range lines {
+		if i < len(lines)-1 {
+			// A trailing carriage return would be part of a "\r\n" line
+			// ending once joined.
+			line = strings.TrimSuffix(line, "\r")
+		}
+		if strings.Contains(line, "\n") {
+			content = append(content, splitLines(line)...)
+			continue
+		}
+		content = append(content, line)
+	}
+	if len(content) == 0 {
+		content = append(content, "")
+	}
+	m.setContent(content)
+}
+
+// setContent replaces the content with the given split lines.
+func (m *Model) setContent(raw []string) {
+	follow := m.Follow && m.AtBottom()
+	prev, prevTop := m.visibleLines(), m.YOffset
+
+	m.raw = raw
+	m.content = m.expandTabs(raw)
+	m.applyFilter()
+
+	// Keep the offset, unless the content no longer reaches that far.
+	if follow || m.YOffset > m.maxYOffset() {
+		m.GotoBottom()
+	}
+	m.markDirty(prev, prevTop)
+}
+
+// AppendContent adds s to the end of the pager's content as one or more new
+// lines. Unlike SetContent only the new text is processed, which makes it
+// suitable for streaming content. The scroll position is kept unless Follow
+// is set. For high performance rendering the Sync command should also be
+// called.
+func (m *Model) AppendContent(s string) {
+	follow := m.Follow && m.AtBottom()
+	prev, prevTop := m.visibleLines(), m.YOffset
+
+	start, from := len(m.content), len(m.lines)
+	raw := splitLines(s)
+	m.raw = append(m.raw, raw...)
+	m.content = append(m.content, m.expandTabs(raw)...)
+	m.filterLines(start)
+	m.matchLines(from)
+
+	if follow {
+		m.GotoBottom()
+	}
+	m.markDirty(prev, prevTop)
+}
+
+// markDirty records the rows whose lines differ from prev, the lines that
+// were visible at offset prevTop before the content changed. All rows are
+// dirty if the offset changed.
+func (m *Model) markDirty(prev []string, prevTop int) {
+	m.dirty = false
+	lines := m.visibleLines()
+	for row := 0; row < max(len(prev), len(lines)); row++ {
+		if prevTop == m.YOffset && row < len(prev) && row < len(lines) && prev[row] == lines[row] {
+			continue
+		}
+		if !m.dirty {
+			m.dirty, m.dirtyFirst = true, row
+		}
+		m.dirtyLast = row
+	}
+}
 
-	if m.YOffset > len(m.lines)-1 {
+// DirtyLines returns the rows of the viewport, from first to last inclusive
+// and relative to its top, whose lines were changed by the last call to
+// SetContent or AppendContent, and whether there are any. This includes rows
+// that were emptied. Scrolling clears the dirty rows, since the scrolling
+// commands render the rows anyway.
+//
+// For high performance rendering, SyncDirty redraws just these rows when
+// content is updated in place.
+func (m Model) DirtyLines() (first, last int, ok bool) {
+	return m.dirtyFirst, m.dirtyLast, m.dirty
+}
+
+// splitLines splits s into lines, normalizing line endings.
+func splitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.Split(s, "\n")
+}
+
+// expandTabs returns the lines with their tabs expanded to TabWidth.
+func (m Model) expandTabs(raw []string) []string {
+	lines := make([]string, len(raw))
+	for i, line := range raw {
+		lines[i] = expandTabs(line, m.TabWidth)
+	}
+	return lines
+}
+
+// SetFilter hides the lines for which keep returns false. Scrolling, search
+// and the scroll position all operate on the remaining lines, while line
+// numbers keep referring to the full content. keep is called with each line
+// as is, including any escape sequences. For high performance rendering the
+// Sync command should also be called.
+func (m *Model) SetFilter(keep func(line string) bool) {
+	follow := m.Follow && m.AtBottom()
+
+	m.filter = keep
+	m.applyFilter()
+
+	if follow || m.YOffset > m.maxYOffset() {
 		m.GotoBottom()
 	}
 }
 
+// ClearFilter shows all lines again. For high performance rendering the Sync
+// command should also be called.
+func (m *Model) ClearFilter() {
+	m.SetFilter(nil)
+}
+
+// applyFilter recomputes the shown lines from the full content.
+func (m *Model) applyFilter() {
+	m.lines, m.lineNumbers = nil, nil
+	m.longestLine = 0
+	m.filterLines(0)
+	m.findMatches()
+}
+
+// filterLines adds the lines of the content from the given index on that pass
+// the filter to the shown lines.
+func (m *Model) filterLines(from int) {
+	for i := from; i < len(m.content); i++ {
+		line := m.content[i]
+		if m.filter != nil {
+			if !m.filter(line) {
+				continue
+			}
+			m.lines = append(m.lines, line)
+			m.lineNumbers = append(m.lineNumbers, i)
+		}
+		m.longestLine = max(m.longestLine, ansi.StringWidth(line))
+	}
+	if m.filter == nil {
+		m.lines = m.content
+	}
+}
+
+// lineNumber returns the 1-based number in the full content of the shown
+// line at index i.
+func (m Model) lineNumber(i int) int {
+	if m.lineNumbers != nil {
+		return m.lineNumbers[i] + 1
+	}
+	return i + 1
+}
+
+// Search finds all lines containing query, ignoring ANSI escape sequences.
+// Matching is case-insensitive unless SearchCaseSensitive is set. Use
+// NextMatch and PrevMatch to scroll between matches. An empty query clears
+// the search.
+func (m *Model) Search(query string) {
+	m.searchQuery = query
+	m.findMatches()
+}
+
+// SearchIncremental is like Search, but when query extends the previous
+// query, as it does while a search is being typed, only the lines that
+// matched the previous query are searched again. This keeps searching as you
+// type snappy in large content.
+func (m *Model) SearchIncremental(query string) {
+	if m.searchQuery == "" || !strings.HasPrefix(query, m.searchQuery) {
+		m.Search(query)
+		return
+	}
+
+	m.searchQuery = query
+	m.currentMatch = -1
+	q := []rune(query)
+	var matches []int
+	for _, i := range m.matches {
+		if m.lineMatches(m.lines[i], q) {
+			matches = append(matches, i)
+		}
+	}
+	m.matches = matches
+}
+
+// MatchCount returns the number of lines matching the current search.
+func (m Model) MatchCount() int {
+	return len(m.matches)
+}
+
+// NextMatch scrolls to the next line matching the current search, wrapping
+// around to the first match after the last one.
+func (m *Model) NextMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.currentMatch = (m.currentMatch + 1) % len(m.matches)
+	m.scrollToMatch(m.matches[m.currentMatch])
+}
+
+// PrevMatch scrolls to the previous line matching the current search,
+// wrapping around to the last match before the first one.
+func (m *Model) PrevMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.currentMatch--
+	if m.currentMatch < 0 {
+		m.currentMatch = len(m.matches) - 1
+	}
+	m.scrollToMatch(m.matches[m.currentMatch])
+}
+
+// scrollToMatch scrolls the matched line at index i to MatchScrollPosition.
+func (m *Model) scrollToMatch(i int) {
+	var row int
+	switch m.MatchScrollPosition {
+	case MatchCenter:
+		row = (m.visibleHeight() - m.rowCount(m.lines[i])) / 2
+	case MatchBottom:
+		row = m.visibleHeight() - m.rowCount(m.lines[i])
+	}
+
+	// Find the top line that puts line i at the row.
+	top, rows := i, 0
+	for top > 0 {
+		n := m.rowCount(m.lines[top-1])
+		if rows+n > row {
+			break
+		}
+		rows += n
+		top--
+	}
+	m.SetYOffset(top)
+}
+
+// findMatches records the lines matching the search query.
+func (m *Model) findMatches() {
+	m.matches = nil
+	m.currentMatch = -1
+	m.matchLines(0)
+}
+
+// matchLines records the lines from the given index on that match the search
+// query.
+func (m *Model) matchLines(from int) {
+	if m.searchQuery == "" {
+		return
+	}
+
+	query := []rune(m.searchQuery)
+	for i := from; i < len(m.lines); i++ {
+		if m.lineMatches(m.lines[i], query) {
+			m.matches = append(m.matches, i)
+		}
+	}
+}
+
+// lineMatches returns whether line contains query, ignoring escape sequences.
+func (m Model) lineMatches(line string, query []rune) bool {
+	return len(findRanges([]rune(ansi.Strip(line)), query, m.SearchCaseSensitive)) > 0
+}
+
+// highlightMatches returns the given visible lines with the search matches
+// highlighted.
+func (m Model) highlightMatches(lines []string) []string {
+	if len(m.matches) == 0 {
+		return lines
+	}
+
+	var (
+		query   = []rune(m.searchQuery)
+		top     = max(0, m.YOffset)
+		current = -1
+		out     = make([]string, len(lines))
+	)
+	if m.currentMatch >= 0 && m.currentMatch < len(m.matches) {
+		current = m.matches[m.currentMatch]
+	}
+	for i, line := range lines {
+		style := m.HighlightStyle
+		if top+i == current {
+			style = m.CurrentMatchStyle
+		}
+		ranges := findRanges([]rune(ansi.Strip(line)), query, m.SearchCaseSensitive)
+		out[i] = highlight(line, ranges, style)
+	}
+	return out
+}
+
+// isEmpty returns whether there are no lines to show, either because there's
+// no content or because the filter hides all of it.
+func (m Model) isEmpty() bool {
+	return len(m.lines) == 0 || len(m.lines) == 1 && m.lines[0] == ""
+}
+
+// visibleHeight returns the number of content lines that fit in the viewport
+// once the style's frame and decorations like the position footer are
+// accounted for. The content area takes up at least one row, even when Height
+// is 0 or less.
+func (m Model) visibleHeight() int {
+	h := m.Height
+	if sh := m.Style.GetHeight(); sh != 0 {
+		h = min(h, sh)
+	}
+	h -= m.Style.GetVerticalFrameSize()
+	if m.ShowPosition {
+		h--
+	}
+	return max(1, h)
+}
+
 // maxYOffset returns the maximum possible value of the y-offset based on the
 // viewport's content and set height.
 func (m Model) maxYOffset() int {
-	return max(0, len(m.lines)-m.Height)
+	h := m.visibleHeight()
+	if !m.SoftWrap {
+		return max(0, len(m.lines)-h)
+	}
+
+	// Find the first of the trailing lines whose rows fit in the viewport.
+	i, rows := len(m.lines), 0
+	for i > 0 {
+		n := m.rowCount(m.lines[i-1])
+		if rows+n > h {
+			break
+		}
+		rows += n
+		i--
+	}
+	return clamp(i, 0, len(m.lines)-1)
 }
 
 // visibleLines returns the lines that should currently be visible in the
 // viewport.
 func (m Model) visibleLines() (lines []string) {
 	if len(m.lines) > 0 {
-		top := max(0, m.YOffset)
-		bottom := clamp(m.YOffset+m.Height, top, len(m.lines))
-		lines = m.lines[top:bottom]
+		top := clamp(m.YOffset, 0, len(m.lines))
+		lines = m.lines[top : top+m.shownLines(top)]
 	}
 	return lines
 }
 
+// gutterWidth returns the width of the line number gutter, including the
+// space separating it from the content.
+func (m Model) gutterWidth() int {
+	if !m.ShowLineNumbers {
+		return 0
+	}
+	return len(strconv.Itoa(len(m.content))) + 1
+}
+
+// contentWidth returns the width available to the content, excluding the
+// style's frame and the scrollbar.
+func (m Model) contentWidth() int {
+	w := m.Width
+	if sw := m.Style.GetWidth(); sw != 0 {
+		w = min(w, sw)
+	}
+	w -= m.Style.GetHorizontalFrameSize()
+	if m.ShowScrollbar {
+		w--
+	}
+	return max(0, w)
+}
+
+// textWidth returns the width available to the lines' text, excluding the
+// line number gutter.
+func (m Model) textWidth() int {
+	return max(0, m.contentWidth()-m.gutterWidth())
+}
+
+// maxXOffset returns the maximum possible value of the x-offset based on the
+// widest line and the viewport's width. It's 0 when soft wrapping.
+func (m Model) maxXOffset() int {
+	if m.SoftWrap {
+		return 0
+	}
+	return max(0, m.longestLine-m.textWidth())
+}
+
+// wrap splits line into the rows it takes up in the view. Without SoftWrap
+// that's always a single row, scrolled by XOffset and cut off at the edge of
+// the viewport.
+func (m Model) wrap(line string) []string {
+	width := m.textWidth()
+	if width <= 0 {
+		return []string{line}
+	}
+	if !m.SoftWrap {
+		line = cutLeft(line, min(m.XOffset, m.maxXOffset()))
+		return []string{closeSGR(ansi.Truncate(line, width, ""))}
+	}
+
+	indent := clamp(m.WrapIndent, 0, width-1)
+	rows := wrapRows(line, width, width-indent)
+	for i := 1; i < len(rows); i++ {
+		rows[i] = strings.Repeat(" ", indent) + rows[i]
+	}
+	rows[len(rows)-1] = closeSGR(rows[len(rows)-1])
+	return rows
+}
+
+// rowCount returns the number of rows line takes up in the view.
+func (m Model) rowCount(line string) int {
+	if !m.SoftWrap {
+		return 1
+	}
+	return len(m.wrap(line))
+}
+
+// shownLines returns the number of lines from the given index on that fit in
+// the viewport, including a line that's cut off at the bottom.
+func (m Model) shownLines(from int) int {
+	h := m.visibleHeight()
+	if !m.SoftWrap {
+		return clamp(len(m.lines)-from, 0, h)
+	}
+
+	n, rows := 0, 0
+	for i := from; i < len(m.lines) && rows < h; i++ {
+		rows += m.rowCount(m.lines[i])
+		n++
+	}
+	return n
+}
+
+// renderRows renders the given visible lines into the rows of the view,
+// wrapping them, highlighting the current line and prefixing them with their
+// line numbers as configured. Continuation rows of a wrapped line get a blank
+// gutter.
+func (m Model) renderRows(lines []string) []string {
+	var (
+		top   = max(0, m.YOffset)
+		width = m.gutterWidth() - 1
+		out   = make([]string, 0, len(lines))
+	)
+	for i, line := range lines {
+		current := m.HighlightCurrentLine && top+i == m.CurrentLine
+		for j, row := range m.wrap(line) {
+			if current {
+				pad := max(0, m.textWidth()-ansi.StringWidth(row))
+				row = m.CurrentLineStyle.Inline(true).Render(row + strings.Repeat(" ", pad))
+			}
+			if m.ShowLineNumbers {
+				var n string
+				if j == 0 {
+					n = strconv.Itoa(m.lineNumber(top + i))
+				}
+				row = m.LineNumberStyle.Render(fmt.Sprintf("%*s", width, n)) + " " + row
+			}
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// scrollbarView renders a vertical scrollbar of the given height. The size of
+// the thumb is proportional to the visible share of the content and its
+// position follows ScrollPercent.
+func (m Model) scrollbarView(height int) string {
+	if height <= 0 {
+		return ""
+	}
+
+	thumb := height
+	if total := len(m.lines); total > height {
+		thumb = clamp(int(math.Round(float64(height*height)/float64(total))), 1, height)
+	}
+	top := int(math.Round(m.ScrollPercent() * float64(height-thumb)))
+
+	rows := make([]string, height)
+	for i := range rows {
+		if i >= top && i < top+thumb {
+			rows[i] = m.ScrollbarThumbStyle.Render(scrollbarThumb)
+		} else {
+			rows[i] = m.ScrollbarStyle.Render(scrollbarTrack)
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+// positionView renders the position footer.
+func (m Model) positionView() string {
+	if m.PositionFormat != nil {
+		return m.PositionFormat(m)
+	}
+	return defaultPositionFormat(m)
+}
+
+// defaultPositionFormat formats the position footer like " 42% (120–140/512) ".
+func defaultPositionFormat(m Model) string {
+	first, last := 0, 0
+	if n := m.VisibleLineCount(); n > 0 {
+		first = m.YOffset + 1
+		last = m.YOffset + n
+	}
+	percent := int(math.Round(m.ScrollPercent() * 100))
+	return fmt.Sprintf(" %d%% (%d–%d/%d) ", percent, first, last, m.TotalLineCount())
+}
+
 // scrollArea returns the scrollable boundaries for high performance rendering.
 func (m Model) scrollArea() (top, bottom int) {
 	top = max(0, m.YPosition)
-	bottom = max(top, top+m.Height)
+	bottom = top + max(1, m.Height)
 	if top > 0 && bottom > top {
 		bottom--
 	}
 	return top, bottom
 }
 
+// SetWidth sets the width of the viewport. Soft wrapped lines are wrapped to
+// the new width, and the scroll position is clamped to the content.
+func (m *Model) SetWidth(w int) {
+	follow := m.Follow && m.AtBottom()
+	m.Width = w
+	m.clampOffsets(follow)
+}
+
+// SetHeight sets the height of the viewport, clamping the scroll position to
+// the content.
+func (m *Model) SetHeight(h int) {
+	follow := m.Follow && m.AtBottom()
+	m.Height = h
+	m.clampOffsets(follow)
+}
+
+// SetSize sets the width and height of the viewport, like SetWidth and
+// SetHeight.
+func (m *Model) SetSize(w, h int) {
+	follow := m.Follow && m.AtBottom()
+	m.Width, m.Height = w, h
+	m.clampOffsets(follow)
+}
+
+// clampOffsets keeps the scroll position within the content after a resize,
+// staying at the bottom if following.
+func (m *Model) clampOffsets(follow bool) {
+	if follow || m.YOffset > m.maxYOffset() {
+		m.GotoBottom()
+	}
+	m.SetXOffset(m.XOffset)
+}
+
 // SetYOffset sets the Y offset.
 func (m *Model) SetYOffset(n int) {
-	m.YOffset = clamp(n, 0, m.maxYOffset())
+	if n := clamp(n, 0, m.maxYOffset()); n != m.YOffset {
+		m.YOffset = n
+		m.dirty = false
+	}
+}
+
+// SetCurrentLine sets the current line to the shown line at index n, scrolling
+// as little as possible to bring it into view. n is clamped to the shown
+// lines.
+func (m *Model) SetCurrentLine(n int) {
+	if len(m.lines) == 0 {
+		m.CurrentLine = 0
+		return
+	}
+	m.CurrentLine = clamp(n, 0, len(m.lines)-1)
+	if m.CurrentLine < m.YOffset {
+		m.SetYOffset(m.CurrentLine)
+		return
+	}
+
+	// Find the first line that can be at the top with the current line still
+	// fully in view.
+	top, rows := m.CurrentLine, m.rowCount(m.lines[m.CurrentLine])
+	for top > m.YOffset {
+		n := m.rowCount(m.lines[top-1])
+		if rows+n > m.visibleHeight() {
+			break
+		}
+		rows += n
+		top--
+	}
+	m.SetYOffset(top)
+}
+
+// SetXOffset sets the X offset.
+func (m *Model) SetXOffset(n int) {
+	m.XOffset = clamp(n, 0, m.maxXOffset())
+}
+
+// ScrollLeft moves the view left by the given number of columns.
+func (m *Model) ScrollLeft(n int) {
+	m.SetXOffset(m.XOffset - n)
+}
+
+// ScrollRight moves the view right by the given number of columns.
+func (m *Model) ScrollRight(n int) {
+	m.SetXOffset(m.XOffset + n)
+}
+
+// ScrollRightWord moves the view right to the start of the next
+// whitespace-delimited column of the widest line, which suits tabular
+// content.
+func (m *Model) ScrollRightWord() {
+	for _, col := range m.columnStarts() {
+		if col > m.XOffset {
+			m.SetXOffset(col)
+			return
+		}
+	}
+	m.SetXOffset(m.maxXOffset())
+}
+
+// ScrollLeftWord moves the view left to the start of the previous
+// whitespace-delimited column of the widest line.
+func (m *Model) ScrollLeftWord() {
+	starts := m.columnStarts()
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i] < m.XOffset {
+			m.SetXOffset(starts[i])
+			return
+		}
+	}
+	m.SetXOffset(0)
+}
+
+// columnStarts returns the columns at which the words of the widest shown
+// line start.
+func (m Model) columnStarts() []int {
+	for _, line := range m.lines {
+		if ansi.StringWidth(line) == m.longestLine {
+			return wordStarts(ansi.Strip(line))
+		}
+	}
+	return nil
 }
 
 // ViewDown moves the view down by the number of lines in the viewport.
@@ -147,7 +928,7 @@ func (m *Model) ViewDown() []string {
 		return nil
 	}
 
-	return m.LineDown(m.Height)
+	return m.LineDown(m.visibleHeight())
 }
 
 // ViewUp moves the view up by one height of the viewport. Basically, "page up".
@@ -156,62 +937,77 @@ func (m *Model) ViewUp() []string {
 		return nil
 	}
 
-	return m.LineUp(m.Height)
+	return m.LineUp(m.visibleHeight())
 }
 
-// HalfViewDown moves the view down by half the height of the viewport.
+// halfPageLines returns the number of lines a half page scrolls by.
+func (m Model) halfPageLines() int {
+	if m.HalfPageLines > 0 {
+		return m.HalfPageLines
+	}
+	return m.visibleHeight() / 2
+}
+
+// HalfViewDown moves the view down by half the height of the viewport, or by
+// HalfPageLines if set.
 func (m *Model) HalfViewDown() (lines []string) {
 	if m.AtBottom() {
 		return nil
 	}
 
-	return m.LineDown(m.Height / 2)
+	return m.LineDown(m.halfPageLines())
 }
 
-// HalfViewUp moves the view up by half the height of the viewport.
+// HalfViewUp moves the view up by half the height of the viewport, or by
+// HalfPageLines if set.
 func (m *Model) HalfViewUp() (lines []string) {
 	if m.AtTop() {
 		return nil
 	}
 
-	return m.LineUp(m.Height / 2)
+	return m.LineUp(m.halfPageLines())
 }
 
 // LineDown moves the view down by the given number of lines.
 func (m *Model) LineDown(n int) (lines []string) {
-	if m.AtBottom() || n == 0 || len(m.lines) == 0 {
+	if m.AtBottom() || n <= 0 || len(m.lines) == 0 {
 		return nil
 	}
 
 	// Make sure the number of lines by which we're going to scroll isn't
 	// greater than the number of lines we actually have left before we reach
 	// the bottom.
+	prev := m.YOffset
 	m.SetYOffset(m.YOffset + n)
 
-	// Gather lines to send off for performance scrolling.
-	bottom := clamp(m.YOffset+m.Height, 0, len(m.lines))
-	top := clamp(m.YOffset+m.Height-n, 0, bottom)
+	// Gather the lines that scrolled into view to send off for performance
+	// scrolling. We may have scrolled by less than n lines.
+	bottom := clamp(m.YOffset+m.visibleHeight(), 0, len(m.lines))
+	top := clamp(max(prev+m.visibleHeight(), m.YOffset), 0, bottom)
 	return m.lines[top:bottom]
 }
 
 // LineUp moves the view down by the given number of lines. Returns the new
 // lines to show.
 func (m *Model) LineUp(n int) (lines []string) {
-	if m.AtTop() || n == 0 || len(m.lines) == 0 {
+	if m.AtTop() || n <= 0 || len(m.lines) == 0 {
 		return nil
 	}
 
 	// Make sure the number of lines by which we're going to scroll isn't
 	// greater than the number of lines we are from the top.
+	prev := m.YOffset
 	m.SetYOffset(m.YOffset - n)
 
-	// Gather lines to send off for performance scrolling.
-	top := max(0, m.YOffset)
-	bottom := clamp(m.YOffset+n, 0, m.maxYOffset())
+	// Gather the lines that scrolled into view to send off for performance
+	// scrolling. We may have scrolled by less than n lines.
+	top := clamp(m.YOffset, 0, len(m.lines))
+	bottom := clamp(min(prev, m.YOffset+m.visibleHeight()), top, len(m.lines))
 	return m.lines[top:bottom]
 }
 
-// TotalLineCount returns the total number of lines (both hidden and visible) within the viewport.
+// TotalLineCount returns the number of lines after filtering, including those
+// scrolled out of view.
 func (m Model) TotalLineCount() int {
 	return len(m.lines)
 }
@@ -221,6 +1017,49 @@ func (m Model) VisibleLineCount() int {
 	return len(m.visibleLines())
 }
 
+// TotalHeight returns the number of rows View renders, including the
+// position footer and the style's frame, so that parent layouts can reserve
+// the right amount of space. It's usually Height, but can be less when Style
+// sets a smaller height, or more when the decorations don't fit in Height.
+func (m Model) TotalHeight() int {
+	if m.HighPerformanceRendering {
+		return max(1, m.Height)
+	}
+	h := m.visibleHeight() + m.Style.GetVerticalFrameSize()
+	if m.ShowPosition {
+		h++
+	}
+	return h
+}
+
+// VisibleContent returns the lines currently in view, without line numbers or
+// any other decorations. Unlike Content, tabs are expanded as they're shown.
+func (m Model) VisibleContent() string {
+	return strings.Join(m.visibleLines(), "\n")
+}
+
+// Content returns the viewport's full content as it was set, including lines
+// hidden by a filter, which suits copying it. Line endings are normalized to
+// "\n", but tabs are kept.
+func (m Model) Content() string {
+	return strings.Join(m.raw, "\n")
+}
+
+// WriteTo writes the viewport's full content to w, the same text as Content
+// returns. It implements io.WriterTo, which makes exporting the content to a
+// file or stdout straightforward.
+func (m Model) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.Content())
+	return int64(n), err
+}
+
+// WriteVisibleTo writes the lines currently in view to w, the same text as
+// VisibleContent returns.
+func (m Model) WriteVisibleTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.VisibleContent())
+	return int64(n), err
+}
+
 // GotoTop sets the viewport to the top position.
 func (m *Model) GotoTop() (lines []string) {
 	if m.AtTop() {
@@ -231,12 +1070,54 @@ func (m *Model) GotoTop() (lines []string) {
 	return m.visibleLines()
 }
 
+// GotoLine scrolls the viewport so that line n (1-based) is the top visible
+// line. The offset is clamped so the viewport never scrolls past the content.
+// While a filter is set, n refers to the full content and the first shown
+// line at or after it is scrolled to.
+func (m *Model) GotoLine(n int) (lines []string) {
+	if m.lineNumbers != nil {
+		m.SetYOffset(sort.SearchInts(m.lineNumbers, n-1))
+		return m.visibleLines()
+	}
+	m.SetYOffset(n - 1)
+	return m.visibleLines()
+}
+
 // GotoBottom sets the viewport to the bottom position.
 func (m *Model) GotoBottom() (lines []string) {
 	m.SetYOffset(m.maxYOffset())
 	return m.visibleLines()
 }
 
+// ReachedTopMsg is sent when scrolling in Update reaches the top of the
+// content.
+type ReachedTopMsg struct{}
+
+// ReachedBottomMsg is sent when scrolling in Update reaches the bottom of the
+// content, which is a good time to load more of it.
+type ReachedBottomMsg struct{}
+
+// AtEdgeMsg is sent when scrolling in Update is attempted while already at
+// the top or bottom of the content, if ReportEdges is set. Hosts can use it
+// to show a bounce or to load more content.
+type AtEdgeMsg struct {
+	Edge Edge
+}
+
+func atEdge(edge Edge) tea.Cmd {
+	return func() tea.Msg {
+		return AtEdgeMsg{Edge: edge}
+	}
+}
+
+func reachedTop() tea.Msg {
+	return ReachedTopMsg{}
+}
+
+func reachedBottom() tea.Msg {
+	return ReachedBottomMsg{}
+}
+
 // Sync tells the renderer where the viewport will be located and requests
 // a render of the current state of the viewport. It should be called for the
 // first render and after a window resize.
@@ -250,6 +1131,27 @@ func Sync(m Model) tea.Cmd {
 	return tea.SyncScrollArea(m.visibleLines(), top, bottom)
 }
 
+// SyncDirty redraws the rows of the viewport reported by DirtyLines, which
+// is cheaper than Sync when content is updated in place. It returns nil when
+// no rows are dirty.
+//
+// For high performance rendering only.
+func SyncDirty(m Model) tea.Cmd {
+	first, last, ok := m.DirtyLines()
+	if !ok {
+		return nil
+	}
+	visible := m.visibleLines()
+	lines := make([]string, last-first+1)
+	for i := range lines {
+		if first+i < len(visible) {
+			lines[i] = visible[first+i]
+		}
+	}
+	top, _ := m.scrollArea()
+	return tea.SyncScrollArea(lines, top+first, top+last)
+}
+
 // ViewDown is a high performance command that moves the viewport up by a given
 // number of lines. Use Model.ViewDown to get the lines that should be rendered.
 // For example:
@@ -289,46 +1191,116 @@ func (m Model) updateAsModel(msg tea.Msg) (Model, tea.Cmd) {
 		m.setInitialValues()
 	}
 
-	var cmd tea.Cmd
+	var (
+		cmd      tea.Cmd
+		atTop    = m.AtTop()
+		atBottom = m.AtBottom()
+
+		// The direction of an attempted vertical scroll: -1 for up and 1 for
+		// down.
+		scroll      = 0
+		prevYOffset = m.YOffset
+		prevLine    = m.CurrentLine
+	)
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.DisableDefaultKeys {
+			break
+		}
+
+		// Digits build up a count for the next motion, like in less and vim:
+		// a line number for GotoTop and GotoBottom, or the number of lines or
+		// columns to move for Up, Down, Left and Right. Any other key uses up
+		// the count.
+		if r := msg.Runes; msg.Type == tea.KeyRunes && !msg.Alt && len(r) == 1 && r[0] >= '0' && r[0] <= '9' {
+			m.count = min(m.count*10+int(r[0]-'0'), maxCount)
+			break
+		}
+		count := m.count
+		m.count = 0
+
 		switch {
 		case key.Matches(msg, m.KeyMap.PageDown):
+			scroll = 1
 			lines := m.ViewDown()
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
 			}
 
 		case key.Matches(msg, m.KeyMap.PageUp):
+			scroll = -1
 			lines := m.ViewUp()
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
 
 		case key.Matches(msg, m.KeyMap.HalfPageDown):
+			scroll = 1
 			lines := m.HalfViewDown()
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
 			}
 
 		case key.Matches(msg, m.KeyMap.HalfPageUp):
+			scroll = -1
 			lines := m.HalfViewUp()
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
 
+		case key.Matches(msg, m.KeyMap.Down) && m.HighlightCurrentLine:
+			scroll = 1
+			m.SetCurrentLine(m.CurrentLine + max(1, count))
+
+		case key.Matches(msg, m.KeyMap.Up) && m.HighlightCurrentLine:
+			scroll = -1
+			m.SetCurrentLine(m.CurrentLine - max(1, count))
+
 		case key.Matches(msg, m.KeyMap.Down):
-			lines := m.LineDown(1)
+			scroll = 1
+			lines := m.LineDown(max(1, count))
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
 			}
 
 		case key.Matches(msg, m.KeyMap.Up):
-			lines := m.LineUp(1)
+			scroll = -1
+			lines := m.LineUp(max(1, count))
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
+
+		case key.Matches(msg, m.KeyMap.Left):
+			m.ScrollLeft(max(1, count))
+
+		case key.Matches(msg, m.KeyMap.Right):
+			m.ScrollRight(max(1, count))
+
+		case key.Matches(msg, m.KeyMap.PageLeft):
+			m.ScrollLeft(m.textWidth())
+
+		case key.Matches(msg, m.KeyMap.PageRight):
+			m.ScrollRight(m.textWidth())
+
+		case key.Matches(msg, m.KeyMap.GotoTop) && count > 0,
+			key.Matches(msg, m.KeyMap.GotoBottom) && count > 0:
+			m.GotoLine(count)
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
+
+		case key.Matches(msg, m.KeyMap.GotoTop):
+			m.GotoTop()
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
+
+		case key.Matches(msg, m.KeyMap.GotoBottom):
+			m.GotoBottom()
+			if m.HighPerformanceRendering {
+				cmd = Sync(m)
+			}
 		}
 
 	case tea.MouseMsg:
@@ -337,12 +1309,14 @@ func (m Model) updateAsModel(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		switch msg.Button {
 		case tea.MouseButtonWheelUp:
+			scroll = -1
 			lines := m.LineUp(m.MouseWheelDelta)
 			if m.HighPerformanceRendering {
 				cmd = ViewUp(m, lines)
 			}
 
 		case tea.MouseButtonWheelDown:
+			scroll = 1
 			lines := m.LineDown(m.MouseWheelDelta)
 			if m.HighPerformanceRendering {
 				cmd = ViewDown(m, lines)
@@ -350,6 +1324,22 @@ func (m Model) updateAsModel(msg tea.Msg) (Model, tea.Cmd) {
 		}
 	}
 
+	if m.ReportEdges && scroll != 0 && m.YOffset == prevYOffset && m.CurrentLine == prevLine {
+		switch {
+		case scroll < 0 && atTop:
+			cmd = tea.Batch(cmd, atEdge(EdgeTop))
+		case scroll > 0 && atBottom:
+			cmd = tea.Batch(cmd, atEdge(EdgeBottom))
+		}
+	}
+
+	switch {
+	case !atTop && m.AtTop():
+		cmd = tea.Batch(cmd, reachedTop)
+	case !atBottom && m.AtBottom():
+		cmd = tea.Batch(cmd, reachedBottom)
+	}
+
 	return m, cmd
 }
 
@@ -363,21 +1353,40 @@ func (m Model) View() string {
 		return strings.Repeat("\n", max(0, m.Height-1))
 	}
 
-	w, h := m.Width, m.Height
-	if sw := m.Style.GetWidth(); sw != 0 {
-		w = min(w, sw)
+	contentWidth := m.contentWidth()
+	linesHeight := m.visibleHeight()
+	style := lipgloss.NewStyle().
+		Width(contentWidth).    // pad to width.
+		Height(linesHeight).    // pad to height.
+		MaxHeight(linesHeight). // truncate height if taller.
+		MaxWidth(contentWidth)  // truncate width if wider.
+	rows := m.renderRows(m.highlightMatches(m.visibleLines()))
+	if m.FillChar != 0 && contentWidth > 0 {
+		fill := string(m.FillChar)
+		fill = ansi.Truncate(fill+strings.Repeat(" ", max(0, contentWidth-ansi.StringWidth(fill))), contentWidth, "")
+		fill = m.FillStyle.Inline(true).Render(fill)
+		for len(rows) < linesHeight {
+			rows = append(rows, fill)
+		}
 	}
-	if sh := m.Style.GetHeight(); sh != 0 {
-		h = min(h, sh)
+	text := strings.Join(rows, "\n")
+	if m.EmptyMessage != "" && m.isEmpty() {
+		style = style.Align(lipgloss.Center, lipgloss.Center)
+		text = m.EmptyMessageStyle.Render(m.EmptyMessage)
+	}
+	contents := style.Render(text)
+	if m.ShowScrollbar {
+		contents = lipgloss.JoinHorizontal(lipgloss.Top, contents, m.scrollbarView(linesHeight))
+		contentWidth++
+	}
+	if m.ShowPosition {
+		footer := lipgloss.NewStyle().
+			Inline(true). // never wrap the footer onto a second row.
+			Width(contentWidth).
+			MaxWidth(contentWidth).
+			Render(m.positionView())
+		contents = lipgloss.JoinVertical(lipgloss.Left, contents, footer)
 	}
-	contentWidth := w - m.Style.GetHorizontalFrameSize()
-	contentHeight := h - m.Style.GetVerticalFrameSize()
-	contents := lipgloss.NewStyle().
-		Width(contentWidth).      // pad to width.
-		Height(contentHeight).    // pad to height.
-		MaxHeight(contentHeight). // truncate height if taller.
-		MaxWidth(contentWidth).   // truncate width if wider.
-		Render(strings.Join(m.visibleLines(), "\n"))
 	return m.Style.
 		UnsetWidth().UnsetHeight(). // Style size already applied in contents.
 		Render(contents)
@@ -0,0 +1,1508 @@
+package viewport
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/muesli/termenv"
+)
+
+func TestSearch(t *testing.T) {
+	vp := New(10, 2)
+	vp.SetContent("Error: one\nok\nerror: two\nok\nok\nERROR: three")
+
+	vp.Search("error")
+	if n := vp.MatchCount(); n != 3 {
+		t.Fatalf("expected 3 matches but got %d", n)
+	}
+
+	for _, want := range []int{0, 2, 4, 0} {
+		vp.NextMatch()
+		if vp.YOffset != want {
+			t.Fatalf("expected offset %d but got %d", want, vp.YOffset)
+		}
+	}
+	for _, want := range []int{4, 2} {
+		vp.PrevMatch()
+		if vp.YOffset != want {
+			t.Fatalf("expected offset %d but got %d", want, vp.YOffset)
+		}
+	}
+
+	vp.SearchCaseSensitive = true
+	vp.Search("error")
+	if n := vp.MatchCount(); n != 1 {
+		t.Fatalf("expected 1 case-sensitive match but got %d", n)
+	}
+
+	vp.Search("")
+	if n := vp.MatchCount(); n != 0 {
+		t.Fatalf("expected search to be cleared but got %d matches", n)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI)
+
+	vp := New(20, 3)
+	vp.HighlightStyle = renderer.NewStyle().Bold(true)
+	vp.CurrentMatchStyle = renderer.NewStyle().Italic(true)
+	vp.SetContent("a cat\n\x1b[31mcats\x1b[0m here\nno match")
+	vp.Search("CAT")
+	vp.NextMatch()
+
+	lines := strings.Split(vp.View(), "\n")
+	if !strings.Contains(lines[0], "a \x1b[3mcat\x1b[0m") {
+		t.Fatalf("expected the current match to be italic in %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "\x1b[31m\x1b[1mcat\x1b[0m\x1b[31ms\x1b[0m here") {
+		t.Fatalf("expected the match to be bold and the line color restored in %q", lines[1])
+	}
+	if strings.Contains(lines[2], "\x1b[") {
+		t.Fatalf("expected no highlight in %q", lines[2])
+	}
+	if w := lipgloss.Width(lines[1]); w != 20 {
+		t.Fatalf("expected highlighted line to be 20 cells wide but got %d", w)
+	}
+}
+
+func TestLineNumbers(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 12; i++ {
+		lines = append(lines, "line")
+	}
+
+	vp := New(10, 3)
+	vp.ShowLineNumbers = true
+	vp.SetContent(strings.Join(lines[:9], "\n"))
+	vp.SetYOffset(6)
+	if view := vp.View(); view != "7 line    \n8 line    \n9 line    " {
+		t.Fatalf("unexpected view %q", view)
+	}
+
+	vp.SetContent(strings.Join(lines, "\n"))
+	if view := vp.View(); view != " 7 line   \n 8 line   \n 9 line   " {
+		t.Fatalf("unexpected view %q", view)
+	}
+	vp.GotoBottom()
+	if view := vp.View(); view != "10 line   \n11 line   \n12 line   " {
+		t.Fatalf("unexpected view %q", view)
+	}
+}
+
+func TestGotoTopAndBottom(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7\n8")
+
+	vp, _ = vp.Update(keyPress('G'))
+	if vp.YOffset != 5 || !vp.AtBottom() {
+		t.Fatalf("expected offset 5 at the bottom but got %d", vp.YOffset)
+	}
+	vp, _ = vp.Update(keyPress('g'))
+	if vp.YOffset != 0 || !vp.AtTop() {
+		t.Fatalf("expected offset 0 at the top but got %d", vp.YOffset)
+	}
+
+	// Content shorter than the viewport never scrolls.
+	vp.SetContent("1\n2")
+	vp.GotoBottom()
+	if vp.YOffset != 0 {
+		t.Fatalf("expected offset 0 for short content but got %d", vp.YOffset)
+	}
+	vp.GotoTop()
+	if vp.YOffset != 0 {
+		t.Fatalf("expected offset 0 for short content but got %d", vp.YOffset)
+	}
+}
+
+func TestGotoLine(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7\n8")
+
+	for _, tc := range []struct{ line, want int }{
+		{4, 3},
+		{0, 0},
+		{-10, 0},
+		{7, 5},
+		{100, 5},
+	} {
+		vp.GotoLine(tc.line)
+		if vp.YOffset != tc.want {
+			t.Fatalf("expected GotoLine(%d) to set offset %d but got %d", tc.line, tc.want, vp.YOffset)
+		}
+	}
+}
+
+func TestScrollbar(t *testing.T) {
+	var lines []string
+	for i := 0; i < 16; i++ {
+		lines = append(lines, "line")
+	}
+
+	vp := New(6, 4)
+	vp.ShowScrollbar = true
+	vp.SetContent(strings.Join(lines, "\n"))
+
+	scrollbar := func() string {
+		var col strings.Builder
+		for _, row := range strings.Split(vp.View(), "\n") {
+			if w := lipgloss.Width(row); w != 6 {
+				t.Fatalf("expected rows to be 6 cells wide but got %d", w)
+			}
+			r := []rune(row)
+			col.WriteRune(r[len(r)-1])
+		}
+		return col.String()
+	}
+
+	for _, tc := range []struct {
+		offset int
+		want   string
+	}{
+		{0, "┃│││"},
+		{4, "│┃││"},
+		{12, "│││┃"},
+	} {
+		vp.SetYOffset(tc.offset)
+		if col := scrollbar(); col != tc.want {
+			t.Fatalf("expected scrollbar %q at offset %d but got %q", tc.want, tc.offset, col)
+		}
+	}
+
+	vp.SetContent("short")
+	if col := scrollbar(); col != "┃┃┃┃" {
+		t.Fatalf("expected a full-height thumb for short content but got %q", col)
+	}
+}
+
+func TestFollow(t *testing.T) {
+	var content []string
+	addLines := func(vp *Model, n int) {
+		for i := 0; i < n; i++ {
+			content = append(content, "line")
+		}
+		vp.SetContent(strings.Join(content, "\n"))
+	}
+
+	vp := New(10, 3)
+	addLines(&vp, 5)
+	if vp.YOffset != 0 {
+		t.Fatalf("expected offset 0 without follow but got %d", vp.YOffset)
+	}
+
+	vp.Follow = true
+	vp.GotoBottom()
+	addLines(&vp, 5)
+	if vp.YOffset != 7 {
+		t.Fatalf("expected offset 7 while following but got %d", vp.YOffset)
+	}
+
+	// Scrolling up pauses following.
+	vp.LineUp(2)
+	addLines(&vp, 5)
+	if vp.YOffset != 5 {
+		t.Fatalf("expected offset 5 after scrolling up but got %d", vp.YOffset)
+	}
+
+	// Returning to the bottom resumes it.
+	vp.GotoBottom()
+	addLines(&vp, 5)
+	if vp.YOffset != 17 {
+		t.Fatalf("expected offset 17 after resuming but got %d", vp.YOffset)
+	}
+}
+
+func TestAppendContent(t *testing.T) {
+	vp := New(10, 2)
+	vp.SetContent("1\n2\n3")
+	vp.LineDown(1)
+
+	vp.AppendContent("4\r\n5")
+	if n := vp.TotalLineCount(); n != 5 {
+		t.Fatalf("expected 5 lines but got %d", n)
+	}
+	if vp.YOffset != 1 {
+		t.Fatalf("expected offset to be kept at 1 but got %d", vp.YOffset)
+	}
+
+	vp.GotoBottom()
+	if view := vp.View(); view != "4         \n5         " {
+		t.Fatalf("expected appended lines to be reachable but got %q", view)
+	}
+}
+
+func BenchmarkAppendContent(b *testing.B) {
+	vp := New(80, 24)
+	for i := 0; i < b.N; i++ {
+		vp.AppendContent("a line of streaming log output")
+	}
+}
+
+func BenchmarkSetContentGrowing(b *testing.B) {
+	vp := New(80, 24)
+	var content strings.Builder
+	for i := 0; i < b.N; i++ {
+		content.WriteString("a line of streaming log output\n")
+		vp.SetContent(content.String())
+	}
+}
+
+func TestSearchIncremental(t *testing.T) {
+	content := "Error: disk\nerror: net\nok\nwarning: disk\nERROR: DISK\n\x1b[31merror\x1b[0m: disk"
+
+	for _, caseSensitive := range []bool{false, true} {
+		incremental, full := New(20, 3), New(20, 3)
+		incremental.SearchCaseSensitive = caseSensitive
+		full.SearchCaseSensitive = caseSensitive
+		incremental.SetContent(content)
+		full.SetContent(content)
+
+		// Typing a query, deleting part of it and typing something else.
+		for _, query := range []string{"e", "er", "err", "error", "error:", "error: d", "error: n", "err", "", "disk"} {
+			incremental.SearchIncremental(query)
+			full.Search(query)
+			if !reflect.DeepEqual(incremental.matches, full.matches) {
+				t.Fatalf("expected matches %v for %q but got %v", full.matches, query, incremental.matches)
+			}
+		}
+	}
+
+	// Incremental results are based on the shown lines.
+	vp := New(20, 3)
+	vp.SetContent(content)
+	vp.SetFilter(func(line string) bool { return !strings.HasPrefix(line, "Error") })
+	vp.SearchIncremental("err")
+	vp.SearchIncremental("error")
+	if vp.MatchCount() != 3 {
+		t.Fatalf("expected 3 matches in the filtered lines but got %d", vp.MatchCount())
+	}
+}
+
+func benchmarkSearch(b *testing.B, search func(vp *Model, query string)) {
+	var content strings.Builder
+	for i := 0; i < 10000; i++ {
+		if i%100 == 0 {
+			content.WriteString("error: connection refused\n")
+		} else {
+			content.WriteString("an uneventful line of log output\n")
+		}
+	}
+	vp := New(80, 24)
+	vp.SetContent(content.String())
+	query := "error: connection"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 1; j <= len(query); j++ {
+			search(&vp, query[:j])
+		}
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	benchmarkSearch(b, (*Model).Search)
+}
+
+func BenchmarkSearchIncremental(b *testing.B) {
+	benchmarkSearch(b, (*Model).SearchIncremental)
+}
+
+func TestSetContentLines(t *testing.T) {
+	for _, lines := range [][]string{
+		nil,
+		{""},
+		{"one", "two", "three"},
+		{"tabs\there", "crlf\r", "embedded\nnewline\r\n", "last\r"},
+		{"", "", ""},
+	} {
+		byString, byLines := New(10, 2), New(10, 2)
+		byString.SetContent(strings.Join(lines, "\n"))
+		byLines.SetContentLines(lines)
+		if !reflect.DeepEqual(byString.content, byLines.content) {
+			t.Fatalf("expected lines %q but got %q", byString.content, byLines.content)
+		}
+		if byString.View() != byLines.View() {
+			t.Fatalf("expected view %q but got %q", byString.View(), byLines.View())
+		}
+	}
+
+	// The caller's slice isn't retained.
+	lines := []string{"a", "b"}
+	vp := New(10, 2)
+	vp.SetContentLines(lines)
+	lines[0] = "changed"
+	if got := vp.Content(); got != "a\nb" {
+		t.Fatalf("expected content %q but got %q", "a\nb", got)
+	}
+}
+
+func benchmarkLines() []string {
+	lines := make([]string, 10000)
+	for i := range lines {
+		lines[i] = "a line of streaming log output"
+	}
+	return lines
+}
+
+func BenchmarkSetContentJoined(b *testing.B) {
+	vp := New(80, 24)
+	lines := benchmarkLines()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vp.SetContent(strings.Join(lines, "\n"))
+	}
+}
+
+func BenchmarkSetContentLines(b *testing.B) {
+	vp := New(80, 24)
+	lines := benchmarkLines()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		vp.SetContentLines(lines)
+	}
+}
+
+func TestAtBottom(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7")
+
+	vp.ViewDown()
+	if vp.AtBottom() {
+		t.Fatalf("expected not to be at the bottom at offset %d", vp.YOffset)
+	}
+	vp.ViewDown()
+	if !vp.AtBottom() {
+		t.Fatal("expected to be at the bottom")
+	}
+	if vp.YOffset != 4 {
+		t.Fatalf("expected offset 4 but got %d", vp.YOffset)
+	}
+	if lines := strings.Split(vp.View(), "\n"); strings.TrimSpace(lines[len(lines)-1]) != "7" {
+		t.Fatalf("expected the last line to be visible but got %q", lines)
+	}
+
+	vp.LineUp(1)
+	if vp.AtBottom() {
+		t.Fatal("expected not to be at the bottom one line above it")
+	}
+}
+
+func TestScrollPercent(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		content string
+		offset  int
+		want    float64
+	}{
+		{"shorter than height", "1\n2", 0, 1},
+		{"equal to height", "1\n2\n3", 0, 1},
+		{"empty", "", 0, 1},
+		{"top", "1\n2\n3\n4\n5\n6\n7", 0, 0},
+		{"middle", "1\n2\n3\n4\n5\n6\n7", 2, 0.5},
+		{"bottom", "1\n2\n3\n4\n5\n6\n7", 4, 1},
+	} {
+		vp := New(10, 3)
+		vp.SetContent(tc.content)
+		vp.SetYOffset(tc.offset)
+		if p := vp.ScrollPercent(); p != tc.want || math.IsNaN(p) {
+			t.Fatalf("%s: expected %v but got %v", tc.name, tc.want, p)
+		}
+	}
+}
+
+func TestSetYOffset(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7")
+
+	for _, tc := range []struct{ offset, want int }{
+		{-5, 0},
+		{2, 2},
+		{1000, 4},
+	} {
+		vp.SetYOffset(tc.offset)
+		if vp.YOffset != tc.want {
+			t.Fatalf("expected SetYOffset(%d) to clamp to %d but got %d", tc.offset, tc.want, vp.YOffset)
+		}
+	}
+}
+
+func TestScrollingGuards(t *testing.T) {
+	for _, content := range []string{"", "1", "1\n2"} {
+		vp := New(10, 3)
+		vp.HighPerformanceRendering = true
+		vp.SetContent(content)
+
+		for _, lines := range [][]string{
+			vp.ViewDown(), vp.ViewUp(),
+			vp.HalfViewDown(), vp.HalfViewUp(),
+			vp.LineDown(1), vp.LineUp(1),
+		} {
+			if lines != nil {
+				t.Fatalf("expected no lines to scroll for %q but got %q", content, lines)
+			}
+		}
+		if cmd := ViewDown(vp, vp.LineDown(1)); cmd != nil {
+			t.Fatalf("expected no command for %q", content)
+		}
+	}
+
+	vp := New(10, 3)
+	if cmd := Sync(vp); cmd != nil {
+		t.Fatal("expected no sync command without content")
+	}
+
+	// An offset set directly out of range must not break rendering.
+	vp.SetContent("1\n2\n3\n4")
+	vp.YOffset = 100
+	if lines := vp.visibleLines(); len(lines) != 0 {
+		t.Fatalf("expected no visible lines but got %q", lines)
+	}
+	_ = vp.View()
+}
+
+func TestScrolledLines(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7")
+
+	// We can only scroll four lines, which brings the last page into view.
+	if lines := vp.LineDown(10); strings.Join(lines, ",") != "5,6,7" {
+		t.Fatalf("unexpected lines scrolled down: %q", lines)
+	}
+	if lines := vp.LineUp(2); strings.Join(lines, ",") != "3,4" {
+		t.Fatalf("unexpected lines scrolled up: %q", lines)
+	}
+	if lines := vp.LineUp(10); strings.Join(lines, ",") != "1,2" {
+		t.Fatalf("unexpected lines scrolled up: %q", lines)
+	}
+}
+
+func TestLineCounts(t *testing.T) {
+	vp := New(10, 3)
+	if total, visible := vp.TotalLineCount(), vp.VisibleLineCount(); total != 0 || visible != 0 {
+		t.Fatalf("expected 0/0 lines without content but got %d/%d", visible, total)
+	}
+
+	vp.SetContent("1\n2\n3\n4\n5")
+	if total, visible := vp.TotalLineCount(), vp.VisibleLineCount(); total != 5 || visible != 3 {
+		t.Fatalf("expected 3/5 lines but got %d/%d", visible, total)
+	}
+
+	// A partial last page, e.g. after making the viewport taller.
+	vp.SetYOffset(2)
+	vp.Height = 5
+	if total, visible := vp.TotalLineCount(), vp.VisibleLineCount(); total != 5 || visible != 3 {
+		t.Fatalf("expected 3/5 lines but got %d/%d", visible, total)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	vp := New(20, 4)
+	vp.ShowPosition = true
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7\n8\n9\n10")
+
+	footer := func() string {
+		lines := strings.Split(vp.View(), "\n")
+		if len(lines) != 4 {
+			t.Fatalf("expected 4 rows but got %d", len(lines))
+		}
+		return strings.TrimRight(lines[3], " ")
+	}
+
+	if got := footer(); got != " 0% (1–3/10)" {
+		t.Fatalf("expected footer %q but got %q", " 0% (1–3/10)", got)
+	}
+
+	vp.GotoBottom()
+	if vp.YOffset != 7 {
+		t.Fatalf("expected the footer to leave room for 3 lines, got offset %d", vp.YOffset)
+	}
+	if got := footer(); got != " 100% (8–10/10)" {
+		t.Fatalf("expected footer %q but got %q", " 100% (8–10/10)", got)
+	}
+
+	vp.PositionFormat = func(m Model) string {
+		return "custom"
+	}
+	if got := footer(); got != "custom" {
+		t.Fatalf("expected custom footer but got %q", got)
+	}
+
+	// Narrow viewports truncate the footer rather than wrapping it.
+	vp.Width = 5
+	vp.PositionFormat = nil
+	if got := footer(); got != " 100%" {
+		t.Fatalf("expected truncated footer but got %q", got)
+	}
+}
+
+func TestTabExpansion(t *testing.T) {
+	vp := New(40, 5)
+	vp.SetContent("a\tb\nabcd\tc\nab\tcd\te\n\tf\n日\tg")
+
+	expected := []string{
+		"a   b",
+		"abcd    c",
+		"ab  cd  e",
+		"    f",
+		"日  g", // wide characters take up two columns
+	}
+	for i, line := range vp.lines {
+		if line != expected[i] {
+			t.Fatalf("expected line %d to be %q but got %q", i, expected[i], line)
+		}
+	}
+
+	vp.AppendContent("a\x1b[1mbc\x1b[0m\td")
+	if got, want := vp.lines[5], "a\x1b[1mbc\x1b[0m d"; got != want {
+		t.Fatalf("expected escape sequences not to count towards the column, got %q", got)
+	}
+
+	vp.TabWidth = 0
+	vp.SetContent("a\tb")
+	if vp.lines[0] != "a\tb" {
+		t.Fatalf("expected tabs to be kept with a zero TabWidth, got %q", vp.lines[0])
+	}
+}
+
+func TestStyleFrame(t *testing.T) {
+	vp := New(10, 5)
+	vp.Style = lipgloss.NewStyle().Border(lipgloss.NormalBorder()).Padding(0, 1)
+	vp.SetContent("1\n2\n3\n4\n5\n6")
+
+	lines := strings.Split(vp.View(), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected the frame to fit in 5 rows but got %d", len(lines))
+	}
+	expected := []string{
+		"┌────────┐",
+		"│ 1      │",
+		"│ 2      │",
+		"│ 3      │",
+		"└────────┘",
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Fatalf("expected row %d to be %q but got %q", i, expected[i], line)
+		}
+	}
+
+	// The border takes up two rows, so the last line is reached at offset 3.
+	vp.GotoBottom()
+	if vp.YOffset != 3 {
+		t.Fatalf("expected offset 3 at the bottom but got %d", vp.YOffset)
+	}
+	if got := strings.Split(vp.View(), "\n")[3]; got != "│ 6      │" {
+		t.Fatalf("expected the last line to be visible but got %q", got)
+	}
+}
+
+func TestKeyMapRemap(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7\n8\n9")
+	vp.KeyMap.PageDown = key.NewBinding(key.WithKeys("n"))
+
+	vp, _ = vp.Update(keyPress('f'))
+	if vp.YOffset != 0 {
+		t.Fatalf("expected the old page down key to be ignored, got offset %d", vp.YOffset)
+	}
+
+	vp, _ = vp.Update(keyPress('n'))
+	if vp.YOffset != 3 {
+		t.Fatalf("expected the remapped key to page down, got offset %d", vp.YOffset)
+	}
+
+	// Disabled bindings don't match at all.
+	vp.KeyMap.PageDown.SetEnabled(false)
+	vp, _ = vp.Update(keyPress('n'))
+	if vp.YOffset != 3 {
+		t.Fatalf("expected a disabled binding to be ignored, got offset %d", vp.YOffset)
+	}
+}
+
+func TestHalfPageLines(t *testing.T) {
+	vp := New(10, 6)
+	vp.SetContent(strings.Repeat("line\n", 29) + "line")
+
+	vp, _ = vp.Update(keyPress('d'))
+	if vp.YOffset != 3 {
+		t.Fatalf("expected half the height by default, got offset %d", vp.YOffset)
+	}
+
+	vp.HalfPageLines = 5
+	vp, _ = vp.Update(keyPress('d'))
+	if vp.YOffset != 8 {
+		t.Fatalf("expected to scroll 5 lines down, got offset %d", vp.YOffset)
+	}
+
+	vp, _ = vp.Update(keyPress('u'))
+	if vp.YOffset != 3 {
+		t.Fatalf("expected to scroll 5 lines up, got offset %d", vp.YOffset)
+	}
+}
+
+func TestSetScrollPercent(t *testing.T) {
+	vp := New(10, 10)
+	vp.SetContent(strings.Repeat("line\n", 56) + "line")
+
+	// One line is the smallest step, so allow for that much rounding.
+	tolerance := 1 / float64(vp.maxYOffset())
+	for _, p := range []float64{0, 0.1, 0.25, 1.0 / 3, 0.5, 0.75, 0.9, 1} {
+		vp.SetScrollPercent(p)
+		if got := vp.ScrollPercent(); math.Abs(got-p) > tolerance {
+			t.Fatalf("expected ScrollPercent to be about %.2f but got %.2f", p, got)
+		}
+	}
+
+	vp.SetScrollPercent(-1)
+	if !vp.AtTop() {
+		t.Fatalf("expected a negative percentage to scroll to the top, got offset %d", vp.YOffset)
+	}
+	vp.SetScrollPercent(2)
+	if !vp.AtBottom() {
+		t.Fatalf("expected a percentage over 1 to scroll to the bottom, got offset %d", vp.YOffset)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	vp := New(20, 2)
+	vp.SetContent("INFO one\nERROR two\nINFO three\nERROR four\nERROR five\nINFO six")
+	vp.SetFilter(func(line string) bool {
+		return strings.Contains(line, "ERROR")
+	})
+
+	if got := vp.TotalLineCount(); got != 3 {
+		t.Fatalf("expected 3 lines after filtering but got %d", got)
+	}
+	if got := strings.Join(vp.visibleLines(), ","); got != "ERROR two,ERROR four" {
+		t.Fatalf("expected only matching lines to be shown but got %q", got)
+	}
+
+	vp.LineDown(5)
+	if !vp.AtBottom() || vp.YOffset != 1 {
+		t.Fatalf("expected to scroll within the filtered lines, got offset %d", vp.YOffset)
+	}
+	if vp.ScrollPercent() != 1 {
+		t.Fatalf("expected to be scrolled fully, got %.2f", vp.ScrollPercent())
+	}
+
+	// Appended lines are filtered too.
+	vp.AppendContent("INFO seven\nERROR eight")
+	if got := vp.TotalLineCount(); got != 4 {
+		t.Fatalf("expected 4 lines after appending but got %d", got)
+	}
+
+	// Line numbers and GotoLine refer to the full content.
+	vp.ShowLineNumbers = true
+	vp.GotoLine(3)
+	if got := strings.Split(vp.View(), "\n")[0]; !strings.HasPrefix(got, "4 ERROR four") {
+		t.Fatalf("expected line 4 at the top but got %q", got)
+	}
+
+	vp.ClearFilter()
+	if got := vp.TotalLineCount(); got != 8 {
+		t.Fatalf("expected all 8 lines after clearing the filter but got %d", got)
+	}
+}
+
+func TestContent(t *testing.T) {
+	vp := New(10, 3)
+	content := "1\n2\n3\n4\n5\n6\n7"
+	vp.SetContent(content)
+	vp.SetYOffset(2)
+
+	if got := vp.VisibleContent(); got != "3\n4\n5" {
+		t.Fatalf("expected the visible lines but got %q", got)
+	}
+	if got := vp.Content(); got != content {
+		t.Fatalf("expected the full content but got %q", got)
+	}
+
+	vp.SetFilter(func(line string) bool { return line != "2" })
+	if got := vp.Content(); got != content {
+		t.Fatalf("expected the full content regardless of the filter but got %q", got)
+	}
+
+	// Tabs are only expanded for display, so copied content keeps them.
+	vp = New(20, 3)
+	vp.SetContent("a\tb\r\nc")
+	vp.AppendContent("\td")
+	if got, expected := vp.Content(), "a\tb\nc\n\td"; got != expected {
+		t.Fatalf("expected content %q but got %q", expected, got)
+	}
+	var b bytes.Buffer
+	if _, err := vp.WriteTo(&b); err != nil || b.String() != vp.Content() {
+		t.Fatalf("expected WriteTo to write %q but got %q (%v)", vp.Content(), b.String(), err)
+	}
+	vp.SetContentLines([]string{"x\ty"})
+	if got := vp.Content(); got != "x\ty" {
+		t.Fatalf("expected content %q but got %q", "x\ty", got)
+	}
+	if got := vp.VisibleContent(); got != "x   y" {
+		t.Fatalf("expected the visible content with tabs expanded but got %q", got)
+	}
+}
+
+func TestDisableDefaultKeys(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6\n7")
+	vp.DisableDefaultKeys = true
+
+	for _, k := range []rune{'j', 'f', ' ', 'd', 'G'} {
+		vp, _ = vp.Update(keyPress(k))
+		if vp.YOffset != 0 {
+			t.Fatalf("expected %q to be ignored, got offset %d", k, vp.YOffset)
+		}
+	}
+
+	// Scrolling through methods still works.
+	vp.LineDown(1)
+	if vp.YOffset != 1 {
+		t.Fatalf("expected offset 1 but got %d", vp.YOffset)
+	}
+}
+
+func TestReachedEdgeMsgs(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("1\n2\n3\n4\n5\n6")
+
+	msgOf := func(cmd tea.Cmd) tea.Msg {
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	var cmd tea.Cmd
+	vp, cmd = vp.Update(keyPress('j'))
+	if msg := msgOf(cmd); msg != nil {
+		t.Fatalf("expected no message away from the edges but got %#v", msg)
+	}
+
+	vp, cmd = vp.Update(keyPress('f'))
+	if _, ok := msgOf(cmd).(ReachedBottomMsg); !ok {
+		t.Fatalf("expected ReachedBottomMsg on reaching the bottom but got %#v", msgOf(cmd))
+	}
+
+	vp, cmd = vp.Update(keyPress('j'))
+	if msg := msgOf(cmd); msg != nil {
+		t.Fatalf("expected no message when already at the bottom but got %#v", msg)
+	}
+
+	vp, _ = vp.Update(keyPress('k'))
+	vp, cmd = vp.Update(keyPress('g'))
+	if _, ok := msgOf(cmd).(ReachedTopMsg); !ok {
+		t.Fatalf("expected ReachedTopMsg on reaching the top but got %#v", msgOf(cmd))
+	}
+
+	_, cmd = vp.Update(keyPress('k'))
+	if msg := msgOf(cmd); msg != nil {
+		t.Fatalf("expected no message when already at the top but got %#v", msg)
+	}
+}
+
+func TestReportEdges(t *testing.T) {
+	vp := New(10, 3)
+	vp.ReportEdges = true
+	vp.MouseWheelEnabled = true
+	vp.SetContent("1\n2\n3\n4\n5\n6")
+
+	msgOf := func(cmd tea.Cmd) tea.Msg {
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	// A scroll that moves doesn't report an edge, even when it reaches one.
+	var cmd tea.Cmd
+	vp, cmd = vp.Update(keyPress('j'))
+	if msg := msgOf(cmd); msg != nil {
+		t.Fatalf("expected no message for a normal scroll but got %#v", msg)
+	}
+	vp, cmd = vp.Update(keyPress('f'))
+	if _, ok := msgOf(cmd).(ReachedBottomMsg); !ok {
+		t.Fatalf("expected ReachedBottomMsg on reaching the bottom but got %#v", msgOf(cmd))
+	}
+
+	// Scrolling further down is clamped and reported.
+	for _, msg := range []tea.Msg{
+		keyPress('j'),
+		keyPress('f'),
+		keyPress('d'),
+		tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown},
+	} {
+		vp, cmd = vp.Update(msg)
+		if msg, ok := msgOf(cmd).(AtEdgeMsg); !ok || msg.Edge != EdgeBottom {
+			t.Fatalf("expected AtEdgeMsg at the bottom edge but got %#v", msgOf(cmd))
+		}
+	}
+	if vp.YOffset != 3 {
+		t.Fatalf("expected offset 3 but got %d", vp.YOffset)
+	}
+
+	// Scrolling up at the bottom is a normal scroll.
+	vp, cmd = vp.Update(keyPress('k'))
+	if msg := msgOf(cmd); msg != nil {
+		t.Fatalf("expected no message for a normal scroll but got %#v", msg)
+	}
+
+	vp.GotoTop()
+	vp, cmd = vp.Update(keyPress('k'))
+	if msg, ok := msgOf(cmd).(AtEdgeMsg); !ok || msg.Edge != EdgeTop {
+		t.Fatalf("expected AtEdgeMsg at the top edge but got %#v", msgOf(cmd))
+	}
+
+	// Moving the current line isn't clamped until it's on the first line.
+	vp.HighlightCurrentLine = true
+	vp.SetCurrentLine(1)
+	vp, cmd = vp.Update(keyPress('k'))
+	if msg := msgOf(cmd); msg != nil || vp.CurrentLine != 0 {
+		t.Fatalf("expected the current line to move without a message but got line %d and %#v", vp.CurrentLine, msg)
+	}
+	vp, cmd = vp.Update(keyPress('k'))
+	if msg, ok := msgOf(cmd).(AtEdgeMsg); !ok || msg.Edge != EdgeTop {
+		t.Fatalf("expected AtEdgeMsg at the top edge but got %#v", msgOf(cmd))
+	}
+
+	// Without ReportEdges, clamped scrolls are ignored.
+	vp.ReportEdges = false
+	_, cmd = vp.Update(keyPress('k'))
+	if msg := msgOf(cmd); msg != nil {
+		t.Fatalf("expected no message without ReportEdges but got %#v", msg)
+	}
+}
+
+func TestWrapIndent(t *testing.T) {
+	vp := New(12, 4)
+	vp.SoftWrap = true
+	vp.WrapIndent = 2
+	vp.ShowLineNumbers = true
+	vp.SetContent("abcdefghijklmnopqrstuvwxyz\nxy\nz")
+
+	rows := func() []string {
+		lines := strings.Split(vp.View(), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " ")
+		}
+		return lines
+	}
+
+	// The gutter takes up 2 columns, leaving 10 for the first row and 8 for
+	// the indented continuation rows.
+	expected := []string{
+		"1 abcdefghij",
+		"    klmnopqr",
+		"    stuvwxyz",
+		"2 xy",
+	}
+	for i, row := range rows() {
+		if row != expected[i] {
+			t.Fatalf("expected row %d to be %q but got %q", i, expected[i], row)
+		}
+	}
+
+	// The bottom is where the trailing lines fit.
+	vp.GotoBottom()
+	if vp.YOffset != 1 {
+		t.Fatalf("expected offset 1 at the bottom but got %d", vp.YOffset)
+	}
+
+	vp.GotoTop()
+	vp.ShowLineNumbers = false
+	if got := rows()[1]; got != "  mnopqrstuv" {
+		t.Fatalf("expected the continuation row to be indented but got %q", got)
+	}
+}
+
+func TestEmptyMessage(t *testing.T) {
+	vp := New(11, 3)
+	vp.EmptyMessage = "empty"
+
+	expected := []string{"           ", "   empty   ", "           "}
+	if got := strings.Split(vp.View(), "\n"); strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Fatalf("expected the message centered but got %q", got)
+	}
+
+	vp.SetContent("")
+	if got := strings.Split(vp.View(), "\n")[1]; got != "   empty   " {
+		t.Fatalf("expected the message for empty content but got %q", got)
+	}
+
+	vp.SetContent("content")
+	if strings.Contains(vp.View(), "empty") {
+		t.Fatalf("expected no message with content but got %q", vp.View())
+	}
+
+	vp.SetFilter(func(string) bool { return false })
+	if !strings.Contains(vp.View(), "empty") {
+		t.Fatalf("expected the message when all lines are filtered out but got %q", vp.View())
+	}
+}
+
+func TestCountPrefix(t *testing.T) {
+	vp := New(10, 5)
+	vp.SetContent(strings.Repeat("line\n", 49) + "line")
+
+	for _, k := range "10G" {
+		vp, _ = vp.Update(keyPress(k))
+	}
+	if vp.YOffset != 9 {
+		t.Fatalf("expected 10G to go to line 10, got offset %d", vp.YOffset)
+	}
+
+	for _, k := range "gg" {
+		vp, _ = vp.Update(keyPress(k))
+	}
+	if vp.YOffset != 0 {
+		t.Fatalf("expected gg to go to the top, got offset %d", vp.YOffset)
+	}
+
+	for _, k := range "3j" {
+		vp, _ = vp.Update(keyPress(k))
+	}
+	if vp.YOffset != 3 {
+		t.Fatalf("expected 3j to move down 3 lines, got offset %d", vp.YOffset)
+	}
+
+	// A key that isn't a motion discards the count.
+	for _, k := range "20xG" {
+		vp, _ = vp.Update(keyPress(k))
+	}
+	if !vp.AtBottom() {
+		t.Fatalf("expected G without a count to go to the bottom, got offset %d", vp.YOffset)
+	}
+}
+
+func TestSetContentKeepsOffset(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent(strings.Repeat("line\n", 9) + "line")
+	vp.SetYOffset(5)
+
+	// Longer content keeps the offset.
+	vp.SetContent(strings.Repeat("line\n", 19) + "line")
+	if vp.YOffset != 5 {
+		t.Fatalf("expected the offset to be kept at 5 but got %d", vp.YOffset)
+	}
+
+	// Shorter content clamps it, so the view stays full.
+	vp.SetContent("1\n2\n3\n4\n5\n6")
+	if vp.YOffset != 3 {
+		t.Fatalf("expected the offset to be clamped to 3 but got %d", vp.YOffset)
+	}
+	if got := vp.VisibleContent(); got != "4\n5\n6" {
+		t.Fatalf("expected the last lines to be visible but got %q", got)
+	}
+
+	vp.SetContent("1")
+	if vp.YOffset != 0 {
+		t.Fatalf("expected the offset to be clamped to 0 but got %d", vp.YOffset)
+	}
+}
+
+func TestHorizontalScroll(t *testing.T) {
+	vp := New(6, 2)
+	vp.SetContent("abcdefghij\n\x1b[31m日本語\x1b[0m")
+
+	rows := func() []string {
+		lines := strings.Split(ansi.Strip(vp.View()), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " ")
+		}
+		return lines
+	}
+	expect := func(expected ...string) {
+		t.Helper()
+		for i, row := range rows() {
+			if row != expected[i] {
+				t.Fatalf("expected row %d to be %q but got %q", i, expected[i], row)
+			}
+		}
+	}
+
+	// Long lines are cut off rather than wrapped.
+	expect("abcdef", "日本語")
+
+	vp, _ = vp.Update(keyPress('l'))
+	vp, _ = vp.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if vp.XOffset != 2 {
+		t.Fatalf("expected offset 2 but got %d", vp.XOffset)
+	}
+	expect("cdefgh", "本語")
+
+	// A wide character cut in half leaves a space.
+	vp, _ = vp.Update(keyPress('h'))
+	expect("bcdefg", " 本語")
+
+	// A count scrolls by that many columns, clamped to the widest line.
+	vp, _ = vp.Update(keyPress('9'))
+	vp, _ = vp.Update(keyPress('l'))
+	if vp.XOffset != 4 {
+		t.Fatalf("expected offset to be clamped to 4 but got %d", vp.XOffset)
+	}
+	expect("efghij", "語")
+
+	vp, _ = vp.Update(tea.KeyMsg{Type: tea.KeyShiftLeft})
+	if vp.XOffset != 0 {
+		t.Fatalf("expected a page left to return to offset 0 but got %d", vp.XOffset)
+	}
+	vp, _ = vp.Update(tea.KeyMsg{Type: tea.KeyShiftRight})
+	if vp.XOffset != 4 {
+		t.Fatalf("expected a page right to scroll to offset 4 but got %d", vp.XOffset)
+	}
+
+	// Horizontal scrolling does nothing while soft wrapping.
+	vp.XOffset = 0
+	vp.SoftWrap = true
+	vp, _ = vp.Update(keyPress('l'))
+	vp, _ = vp.Update(tea.KeyMsg{Type: tea.KeyShiftRight})
+	if vp.XOffset != 0 {
+		t.Fatalf("expected horizontal keys to be ignored when soft wrapping but got offset %d", vp.XOffset)
+	}
+	expect("abcdef", "ghij")
+}
+
+func TestScrollWord(t *testing.T) {
+	vp := New(10, 3)
+	vp.SetContent("NAME     SIZE  MODIFIED     OWNER\na.txt    12    2024-01-01   root")
+
+	// Scrolling lands on column starts until the widest line's end is in
+	// view.
+	for _, expected := range []int{9, 15, 23, 23} {
+		vp.ScrollRightWord()
+		if vp.XOffset != expected {
+			t.Fatalf("expected offset %d but got %d", expected, vp.XOffset)
+		}
+	}
+	if got := strings.Split(vp.View(), "\n")[0]; got != "     OWNER" {
+		t.Fatalf("expected the last column to be in view but got %q", got)
+	}
+
+	for _, expected := range []int{15, 9, 0, 0} {
+		vp.ScrollLeftWord()
+		if vp.XOffset != expected {
+			t.Fatalf("expected offset %d but got %d", expected, vp.XOffset)
+		}
+	}
+
+	vp.ScrollRightWord()
+	if got := strings.Split(vp.View(), "\n")[1]; got != "12    2024" {
+		t.Fatalf("expected the view to start at the second column but got %q", got)
+	}
+}
+
+func TestCurrentLine(t *testing.T) {
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI)
+
+	vp := New(4, 3)
+	vp.HighlightCurrentLine = true
+	vp.CurrentLineStyle = renderer.NewStyle().Bold(true)
+	vp.SetContent("0\n1\n2\n3\n4\n5\n6\n7\n8\n9")
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	up := tea.KeyMsg{Type: tea.KeyUp}
+
+	// Moving within the view doesn't scroll.
+	vp, _ = vp.Update(down)
+	vp, _ = vp.Update(down)
+	if vp.CurrentLine != 2 || vp.YOffset != 0 {
+		t.Fatalf("expected current line 2 at offset 0 but got %d at %d", vp.CurrentLine, vp.YOffset)
+	}
+
+	// Moving off the bottom scrolls just enough to follow.
+	vp, _ = vp.Update(down)
+	vp, _ = vp.Update(down)
+	if vp.CurrentLine != 4 || vp.YOffset != 2 {
+		t.Fatalf("expected current line 4 at offset 2 but got %d at %d", vp.CurrentLine, vp.YOffset)
+	}
+	rows := strings.Split(vp.View(), "\n")
+	if expected := "\x1b[1m4   \x1b[0m"; rows[2] != expected {
+		t.Fatalf("expected the current line to be highlighted as %q but got %q", expected, rows[2])
+	}
+	if rows[0] != "2   " {
+		t.Fatalf("expected other lines not to be highlighted but got %q", rows[0])
+	}
+
+	// Moving off the top scrolls up, and the current line stays in bounds.
+	vp, _ = vp.Update(keyPress('3'))
+	vp, _ = vp.Update(up)
+	if vp.CurrentLine != 1 || vp.YOffset != 1 {
+		t.Fatalf("expected current line 1 at offset 1 but got %d at %d", vp.CurrentLine, vp.YOffset)
+	}
+	vp, _ = vp.Update(keyPress('G'))
+	vp.SetCurrentLine(20)
+	if vp.CurrentLine != 9 || vp.YOffset != 7 {
+		t.Fatalf("expected current line 9 at offset 7 but got %d at %d", vp.CurrentLine, vp.YOffset)
+	}
+
+	// Soft wrapped lines are kept fully in view.
+	vp = New(4, 3)
+	vp.HighlightCurrentLine = true
+	vp.SoftWrap = true
+	vp.SetContent("a\nb\ncdefghij")
+	vp.SetCurrentLine(2)
+	if vp.YOffset != 1 {
+		t.Fatalf("expected offset 1 to fit the wrapped line but got %d", vp.YOffset)
+	}
+}
+
+func TestTotalHeight(t *testing.T) {
+	vp := New(10, 5)
+	vp.SetContent("1\n2\n3\n4\n5\n6")
+
+	check := func(expected int) {
+		t.Helper()
+		if got := vp.TotalHeight(); got != expected {
+			t.Fatalf("expected a total height of %d but got %d", expected, got)
+		}
+		if rendered := lipgloss.Height(vp.View()); rendered != expected {
+			t.Fatalf("expected the view to be %d rows but got %d", expected, rendered)
+		}
+	}
+
+	check(5)
+	vp.ShowPosition = true
+	check(5)
+	vp.Style = lipgloss.NewStyle().Border(lipgloss.NormalBorder())
+	check(5)
+	vp.ShowPosition = false
+	check(5)
+
+	// A style height smaller than the viewport's shrinks it.
+	vp.Style = vp.Style.Height(3)
+	check(3)
+
+	// Decorations that don't fit push the view past its height.
+	vp.Height = 2
+	vp.Style = lipgloss.NewStyle().Border(lipgloss.NormalBorder())
+	vp.ShowPosition = true
+	check(4)
+}
+
+func TestFillChar(t *testing.T) {
+	vp := New(5, 4)
+	vp.SetContent("a\nb")
+	vp.FillChar = '~'
+	vp.FillStyle = lipgloss.NewStyle()
+
+	expected := []string{"a    ", "b    ", "~    ", "~    "}
+	for i, row := range strings.Split(vp.View(), "\n") {
+		if row != expected[i] {
+			t.Fatalf("expected row %d to be %q but got %q", i, expected[i], row)
+		}
+	}
+
+	// The whole row takes on the fill style's background.
+	renderer := lipgloss.NewRenderer(io.Discard)
+	renderer.SetColorProfile(termenv.ANSI)
+	vp.FillChar = ' '
+	vp.FillStyle = renderer.NewStyle().Background(lipgloss.Color("4"))
+	rows := strings.Split(vp.View(), "\n")
+	if rows[1] != "b    " {
+		t.Fatalf("expected content rows not to be filled but got %q", rows[1])
+	}
+	if expected := "\x1b[44m     \x1b[0m"; rows[3] != expected {
+		t.Fatalf("expected the padded row to be %q but got %q", expected, rows[3])
+	}
+
+	// Without a fill character the rows are left blank.
+	vp.FillChar = 0
+	if rows := strings.Split(vp.View(), "\n"); rows[3] != "     " {
+		t.Fatalf("expected a blank row but got %q", rows[3])
+	}
+}
+
+func TestMatchScrollPosition(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+	lines[10] = "match"
+
+	for _, tc := range []struct {
+		position MatchPosition
+		height   int
+		row      int
+	}{
+		{MatchTop, 5, 0},
+		{MatchCenter, 5, 2},
+		{MatchCenter, 4, 1},
+		{MatchBottom, 5, 4},
+		{MatchBottom, 4, 3},
+	} {
+		vp := New(10, tc.height)
+		vp.MatchScrollPosition = tc.position
+		vp.SetContent(strings.Join(lines, "\n"))
+		vp.Search("match")
+		vp.NextMatch()
+
+		rows := strings.Split(vp.VisibleContent(), "\n")
+		if tc.row >= len(rows) || rows[tc.row] != "match" {
+			t.Fatalf("expected the match on row %d with position %d and height %d but got %q", tc.row, tc.position, tc.height, rows)
+		}
+	}
+
+	// Matches near the top can't be scrolled further down than the top.
+	vp := New(10, 5)
+	vp.MatchScrollPosition = MatchBottom
+	vp.SetContent("a\nmatch\nb\nc\nd\ne\nf")
+	vp.Search("match")
+	vp.NextMatch()
+	if vp.YOffset != 0 {
+		t.Fatalf("expected offset 0 but got %d", vp.YOffset)
+	}
+}
+
+func TestDirtyLines(t *testing.T) {
+	vp := New(10, 3)
+	vp.HighPerformanceRendering = true
+	vp.SetContent("a\nb\nc\nd\ne")
+
+	dirty := func(first, last int) {
+		t.Helper()
+		f, l, ok := vp.DirtyLines()
+		if !ok || f != first || l != last {
+			t.Fatalf("expected rows %d–%d to be dirty but got %d–%d (%v)", first, last, f, l, ok)
+		}
+		if SyncDirty(vp) == nil {
+			t.Fatal("expected a command to redraw the dirty rows")
+		}
+	}
+	clean := func() {
+		t.Helper()
+		if _, _, ok := vp.DirtyLines(); ok {
+			t.Fatal("expected no dirty rows")
+		}
+		if SyncDirty(vp) != nil {
+			t.Fatal("expected no command without dirty rows")
+		}
+	}
+
+	// Lines changed in place, including ones outside of the view.
+	vp.SetContent("a\nB\nC\nd\nE")
+	dirty(1, 2)
+	vp.SetContent("a\nB\nC\nd\nE")
+	clean()
+
+	// Lines appended into the view, and lines removed from it.
+	vp.SetContent("a")
+	dirty(1, 2)
+	vp.AppendContent("b")
+	dirty(1, 1)
+	vp.AppendContent("c\nd")
+	dirty(2, 2)
+
+	// Changing the offset dirties every row, and scrolling clears them.
+	vp.SetContent("a\nb\nc\nd\ne")
+	vp.GotoBottom()
+	clean()
+	vp.SetContent("a\nb")
+	dirty(0, 2)
+}
+
+func TestStyledContentClipping(t *testing.T) {
+	// Wrapped rows close the styling at their end and reopen it on the next
+	// row, so that it doesn't leak into the padding.
+	vp := New(4, 3)
+	vp.SoftWrap = true
+	vp.SetContent("\x1b[1mab\x1b[31mcdefgh\x1b[0mij")
+	expected := []string{
+		"\x1b[1mab\x1b[31mcd\x1b[0m",
+		"\x1b[1m\x1b[31mefgh\x1b[0m",
+		"ij  ",
+	}
+	for i, row := range strings.Split(vp.View(), "\n") {
+		if row != expected[i] {
+			t.Fatalf("expected row %d to be %q but got %q", i, expected[i], row)
+		}
+	}
+
+	// Clipped lines are closed at the edge, whether or not they were closed
+	// past it or at all.
+	vp = New(4, 2)
+	vp.SetContent("\x1b[31mabcdef\x1b[0m\n\x1b[32mabcdef")
+	expected = []string{
+		"\x1b[31mabcd\x1b[0m",
+		"\x1b[32mabcd\x1b[0m",
+	}
+	for i, row := range strings.Split(vp.View(), "\n") {
+		if row != expected[i] {
+			t.Fatalf("expected row %d to be %q but got %q", i, expected[i], row)
+		}
+	}
+
+	// As are lines that are scrolled into the middle of an escape run.
+	vp.SetXOffset(2)
+	if row := strings.Split(vp.View(), "\n")[1]; row != "\x1b[32mcdef\x1b[0m" {
+		t.Fatalf("expected the scrolled row to keep its styling but got %q", row)
+	}
+}
+
+func TestSetWidthAndHeight(t *testing.T) {
+	vp := New(10, 2)
+	vp.SoftWrap = true
+	vp.SetContent("aaaaaaaaaa\nbbbbbbbbbb\ncc")
+	vp.GotoBottom()
+	if vp.YOffset != 1 {
+		t.Fatalf("expected offset 1 at the bottom but got %d", vp.YOffset)
+	}
+
+	// Narrowing re-wraps the lines, leaving more room to scroll.
+	vp.SetWidth(5)
+	if view := vp.View(); view != "bbbbb\nbbbbb" {
+		t.Fatalf("expected the lines to be wrapped to the new width but got %q", view)
+	}
+	vp.GotoBottom()
+	if vp.YOffset != 2 {
+		t.Fatalf("expected offset 2 at the bottom but got %d", vp.YOffset)
+	}
+
+	// Widening re-wraps them again and clamps the offset.
+	vp.SetWidth(20)
+	if vp.YOffset != 1 {
+		t.Fatalf("expected the offset to be clamped to 1 but got %d", vp.YOffset)
+	}
+	vp.SetHeight(5)
+	if vp.YOffset != 0 {
+		t.Fatalf("expected the offset to be clamped to 0 but got %d", vp.YOffset)
+	}
+
+	// A followed viewport stays at the bottom.
+	vp.Follow = true
+	vp.SetHeight(1)
+	if !vp.AtBottom() || vp.YOffset != 2 {
+		t.Fatalf("expected the viewport to stay at the bottom but got offset %d", vp.YOffset)
+	}
+
+	// The horizontal offset is clamped too.
+	vp = New(4, 1)
+	vp.SetContent("abcdefgh")
+	vp.SetXOffset(4)
+	vp.SetWidth(6)
+	if vp.XOffset != 2 {
+		t.Fatalf("expected the horizontal offset to be clamped to 2 but got %d", vp.XOffset)
+	}
+}
+
+func TestSetSize(t *testing.T) {
+	vp := New(10, 2)
+	vp.SetContent("1\n2\n3\n4\n5")
+	vp.GotoBottom()
+
+	vp.SetSize(20, 4)
+	if vp.Width != 20 || vp.Height != 4 {
+		t.Fatalf("expected a size of 20x4 but got %dx%d", vp.Width, vp.Height)
+	}
+	if vp.YOffset != 1 {
+		t.Fatalf("expected the offset to be clamped to 1 but got %d", vp.YOffset)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	vp := New(10, 2)
+	vp.SetContent("one\n\x1b[1mtwo\x1b[0m\nthree\nfour")
+	vp.SetFilter(func(line string) bool { return line != "four" })
+	vp.LineDown(1)
+
+	var _ io.WriterTo = vp
+
+	var buf bytes.Buffer
+	n, err := vp.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if expected := "one\n\x1b[1mtwo\x1b[0m\nthree\nfour"; buf.String() != expected {
+		t.Fatalf("expected the full content %q but got %q", expected, buf.String())
+	}
+	if n != int64(buf.Len()) {
+		t.Fatalf("expected %d bytes to be reported but got %d", buf.Len(), n)
+	}
+
+	buf.Reset()
+	if _, err := vp.WriteVisibleTo(&buf); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if expected := "\x1b[1mtwo\x1b[0m\nthree"; buf.String() != expected {
+		t.Fatalf("expected the visible lines %q but got %q", expected, buf.String())
+	}
+}
+
+func keyPress(key rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}}
+}
+
+func TestNonPositiveHeight(t *testing.T) {
+	content := strings.TrimSuffix(strings.Repeat("line\n", 20), "\n")
+	for _, h := range []int{0, -3} {
+		for _, setup := range []func(*Model){
+			func(m *Model) {},
+			func(m *Model) { m.SoftWrap = true },
+			func(m *Model) { m.ShowPosition = true; m.ShowScrollbar = true },
+			func(m *Model) { m.ShowLineNumbers = true; m.FillChar = '~' },
+			func(m *Model) { m.HighlightCurrentLine = true },
+			func(m *Model) { m.HighPerformanceRendering = true },
+			func(m *Model) { m.Style = lipgloss.NewStyle().Border(lipgloss.NormalBorder()) },
+		} {
+			m := New(10, h)
+			setup(&m)
+			m.SetContent(content)
+			if !m.HighPerformanceRendering {
+				if rows, expected := lipgloss.Height(m.View()), m.TotalHeight(); rows != expected {
+					t.Fatalf("height %d: expected %d rows but got %d", h, expected, rows)
+				}
+			}
+			_ = Sync(m)
+			m.ViewDown()
+			m.HalfViewDown()
+			m.LineDown(3)
+			m.GotoBottom()
+			if m.VisibleLineCount() != 1 || m.YOffset != m.TotalLineCount()-1 {
+				t.Fatalf("height %d: expected the last line to show at the bottom, but the offset is %d with %d lines visible", h, m.YOffset, m.VisibleLineCount())
+			}
+			m.ViewUp()
+			m.HalfViewUp()
+			m.LineUp(3)
+			m.GotoTop()
+			m.SetCurrentLine(5)
+			m.Search("line")
+			m.NextMatch()
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+			m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgDown})
+			_ = m.View()
+			_ = m.TotalHeight()
+		}
+	}
+}
+
+func TestZeroValueSettingsSurviveUpdate(t *testing.T) {
+	var vp Model
+	vp.TabWidth = 8
+	vp.HighlightStyle = lipgloss.NewStyle().Underline(true)
+	vp.SetContent("a\tb")
+
+	vp, _ = vp.Update(nil)
+	if vp.TabWidth != 8 {
+		t.Fatalf("expected TabWidth 8 to survive the first Update but got %d", vp.TabWidth)
+	}
+	if !vp.HighlightStyle.GetUnderline() || vp.HighlightStyle.GetReverse() {
+		t.Fatal("expected HighlightStyle to survive the first Update")
+	}
+	if got := vp.VisibleContent(); got != "a       b" {
+		t.Fatalf("expected tabs expanded to 8 columns but got %q", got)
+	}
+
+	// Unset styles still get their defaults.
+	if !vp.CurrentLineStyle.GetReverse() {
+		t.Fatal("expected CurrentLineStyle to be defaulted")
+	}
+}